@@ -0,0 +1,131 @@
+package czds
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// AuditRecord is a single HistoryEntry of a request flattened with the request it belongs to
+type AuditRecord struct {
+	RequestID string    `json:"requestId"`
+	TLD       string    `json:"tld"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+}
+
+// auditRecords flattens the history of info into AuditRecords
+func auditRecords(info *RequestsInfo) []AuditRecord {
+	var tld string
+	if info.TLD != nil {
+		tld = info.TLD.TLD
+	}
+	records := make([]AuditRecord, 0, len(info.History))
+	for _, event := range info.History {
+		records = append(records, AuditRecord{
+			RequestID: info.RequestID,
+			TLD:       tld,
+			Timestamp: event.Timestamp,
+			Action:    event.Action,
+		})
+	}
+	return records
+}
+
+// ExportAuditLog writes the history of every request matching filter to w as AuditRecords in format,
+// either FormatJSONL or FormatCSV. A nil filter exports all requests.
+// The request details are fetched and written one page at a time so the log is never held in memory
+func (c *Client) ExportAuditLog(ctx context.Context, w io.Writer, format string, filter *RequestsFilter) error {
+	var write func(AuditRecord) error
+	var flush func() error
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		write = func(record AuditRecord) error {
+			return enc.Encode(record)
+		}
+		flush = func() error { return nil }
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		err := cw.Write([]string{"requestId", "tld", "timestamp", "action"})
+		if err != nil {
+			return err
+		}
+		write = func(record AuditRecord) error {
+			return cw.Write([]string{record.RequestID, record.TLD, record.Timestamp.Format(time.RFC3339), record.Action})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return fmt.Errorf("unsupported audit log format %q", format)
+	}
+
+	pageSize := DefaultPageSize
+	if filter != nil && filter.Pagination.Size > 0 {
+		pageSize = filter.Pagination.Size
+	}
+	ids := make([]string, 0, pageSize)
+	writePage := func() error {
+		infos, err := c.GetRequestInfoBatch(ctx, ids, DefaultInfoParallel)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			for _, record := range auditRecords(info) {
+				err = write(record)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		ids = ids[:0]
+		return flush()
+	}
+
+	err := c.ForEachRequest(ctx, filter, func(request Request) error {
+		ids = append(ids, request.RequestID)
+		if len(ids) < pageSize {
+			return nil
+		}
+		return writePage()
+	})
+	if err != nil {
+		return err
+	}
+	return writePage()
+}
+
+// AggregateHistory returns the history of every request of the account as AuditRecords sorted oldest first
+// giving a chronological feed of all activity. Unlike ExportAuditLog() the whole history is held in memory
+func (c *Client) AggregateHistory(ctx context.Context) ([]AuditRecord, error) {
+	requests, err := c.GetAllRequests(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.RequestID)
+	}
+	infos, err := c.GetRequestInfoBatch(ctx, ids, DefaultInfoParallel)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]AuditRecord, 0, len(infos))
+	for _, info := range infos {
+		records = append(records, auditRecords(info)...)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		}
+		return records[i].RequestID < records[j].RequestID
+	})
+	return records, nil
+}
@@ -0,0 +1,243 @@
+package czds
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPageSize is the number of requests fetched per page by ForEachRequest when the filter does not set one
+const DefaultPageSize = 100
+
+// DefaultInfoParallel is the number of requests fetched at once by GetRequestInfoBatch when parallel is not set
+const DefaultInfoParallel = 5
+
+// ForEachRequest pages through all requests matching filter calling fn for each one in order
+// a nil filter returns all requests sorted by creation date, newest first. Page sizes over MaxPageSize are clamped,
+// and paging continues until an empty page or the reported total is reached, so it does not matter how many
+// requests each page returned. Iteration stops at the first error returned by fn, which is returned
+func (c *Client) ForEachRequest(ctx context.Context, filter *RequestsFilter, fn func(Request) error) error {
+	var f RequestsFilter
+	if filter != nil {
+		f = *filter
+	} else {
+		f = RequestsFilter{
+			Status: RequestAll,
+			Sort: RequestsSort{
+				Field:     SortByCreated,
+				Direction: SortDesc,
+			},
+		}
+	}
+	if f.Pagination.Size < 1 {
+		f.Pagination.Size = DefaultPageSize
+	}
+	f = *c.clampPageSize(ctx, &f)
+
+	var seen int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		requests, err := c.getRequests(ctx, &f)
+		if err != nil {
+			return err
+		}
+		if len(requests.Requests) == 0 {
+			return nil
+		}
+		for _, request := range requests.Requests {
+			err = fn(request)
+			if err != nil {
+				return err
+			}
+		}
+		seen += int64(len(requests.Requests))
+		if requests.TotalRequests > 0 && seen >= requests.TotalRequests {
+			return nil
+		}
+		f.Pagination.Page++
+	}
+}
+
+// GetAllRequests returns all requests matching filter by paging through them with ForEachRequest()
+func (c *Client) GetAllRequests(ctx context.Context, filter *RequestsFilter) ([]Request, error) {
+	requests := make([]Request, 0, DefaultPageSize)
+	err := c.ForEachRequest(ctx, filter, func(request Request) error {
+		requests = append(requests, request)
+		return nil
+	})
+	return requests, err
+}
+
+// SFTPRequests returns all of the account's requests for TLDs that are delivered over SFTP
+// these zones can not be downloaded over HTTPS with the download methods of this package
+func (c *Client) SFTPRequests() ([]Request, error) {
+	return c.requestsByDelivery(true)
+}
+
+// HTTPSRequests returns all of the account's requests for TLDs that are delivered over HTTPS
+func (c *Client) HTTPSRequests() ([]Request, error) {
+	return c.requestsByDelivery(false)
+}
+
+// requestsByDelivery returns all requests whose SFTP flag matches sftp
+func (c *Client) requestsByDelivery(sftp bool) ([]Request, error) {
+	requests := make([]Request, 0)
+	err := c.ForEachRequest(context.Background(), nil, func(request Request) error {
+		if request.SFTP == sftp {
+			requests = append(requests, request)
+		}
+		return nil
+	})
+	return requests, err
+}
+
+// GetRequestInfoBatch fetches GetRequestInfo() for each of requestIDs with up to parallel requests at once
+// the returned slice is in the same order as requestIDs. If any lookups fail the first error is returned
+// along with the results that did succeed, failed lookups are left nil.
+// If ctx is done no further lookups are started and ctx.Err() is returned with the partial results
+func (c *Client) GetRequestInfoBatch(ctx context.Context, requestIDs []string, parallel int) ([]*RequestsInfo, error) {
+	if parallel < 1 {
+		parallel = DefaultInfoParallel
+	}
+
+	infos := make([]*RequestsInfo, len(requestIDs))
+	errs := make([]error, len(requestIDs))
+	forEachParallel(ctx, len(requestIDs), parallel, func(n int) {
+		infos[n], errs[n] = c.getRequestInfo(ctx, requestIDs[n])
+		if errs[n] != nil {
+			infos[n] = nil
+		}
+	})
+
+	if err := ctx.Err(); err != nil {
+		return infos, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return infos, err
+		}
+	}
+	return infos, nil
+}
+
+// DefaultStatusParallel is the number of statuses fetched at once by GetRequestsByStatuses
+const DefaultStatusParallel = 3
+
+// GetRequestsByStatuses returns all requests with any of statuses, which should be Request* constants
+// each status is paged through concurrently using filter's search and page size, with filter.Status ignored.
+// The results are merged, de-duplicated by RequestID keeping the most recently updated copy in case a request
+// changed status between the calls, and sorted by filter.Sort. A nil filter sorts by creation date, newest first
+func (c *Client) GetRequestsByStatuses(ctx context.Context, statuses []string, filter *RequestsFilter) ([]Request, error) {
+	var base RequestsFilter
+	if filter != nil {
+		base = *filter
+	} else {
+		base.Sort = RequestsSort{
+			Field:     SortByCreated,
+			Direction: SortDesc,
+		}
+	}
+
+	results := make([][]Request, len(statuses))
+	errs := make([]error, len(statuses))
+	forEachParallel(ctx, len(statuses), DefaultStatusParallel, func(n int) {
+		f := base
+		f.Status = statuses[n]
+		f.Pagination.Page = 0
+		results[n], errs[n] = c.GetAllRequests(ctx, &f)
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byID := make(map[string]Request)
+	for _, requests := range results {
+		for _, request := range requests {
+			existing, ok := byID[request.RequestID]
+			if !ok || request.LastUpdated.After(existing.LastUpdated) {
+				byID[request.RequestID] = request
+			}
+		}
+	}
+	merged := make([]Request, 0, len(byID))
+	for _, request := range byID {
+		merged = append(merged, request)
+	}
+	sortRequests(merged, base.Sort)
+	return merged, nil
+}
+
+// sortRequests sorts reqs in place by by.Field and by.Direction as the API would, ties are ordered by RequestID
+// an unknown or empty field sorts by creation date
+func sortRequests(reqs []Request, by RequestsSort) {
+	compare := func(a, b *Request) int {
+		switch by.Field {
+		case SortByTLD:
+			return strings.Compare(a.TLD, b.TLD)
+		case SortByStatus:
+			return strings.Compare(strings.ToLower(a.Status), strings.ToLower(b.Status))
+		case SortByLastUpdated:
+			return compareTimes(a.LastUpdated, b.LastUpdated)
+		case SortByExpiration:
+			return compareTimes(a.Expired, b.Expired)
+		}
+		return compareTimes(a.Created, b.Created)
+	}
+	desc := strings.EqualFold(by.Direction, SortDesc)
+	sort.Slice(reqs, func(i, j int) bool {
+		cmp := compare(&reqs[i], &reqs[j])
+		if cmp == 0 {
+			return reqs[i].RequestID < reqs[j].RequestID
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// compareTimes returns -1, 0, or 1 if a is before, equal to, or after b
+func compareTimes(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// forEachParallel calls fn with every index in [0, n) using up to parallel goroutines
+// once ctx is done no new calls are started and the indexes not yet started are skipped
+func forEachParallel(ctx context.Context, n, parallel int, fn func(i int)) {
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i)
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+}
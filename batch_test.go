@@ -0,0 +1,39 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetRequestInfoBatchDeadline(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+			t.Errorf("request to %s was not cancelled", r.URL.Path)
+		}
+	}))
+
+	ids := []string{"1", "2", "3", "4", "5"}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	infos, err := client.GetRequestInfoBatch(ctx, ids, 2)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetRequestInfoBatch took %s to return after the deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(infos) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(infos), len(ids))
+	}
+	for n, info := range infos {
+		if info != nil {
+			t.Errorf("request %s has info %+v from a lookup that never finished", ids[n], info)
+		}
+	}
+}
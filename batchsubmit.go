@@ -0,0 +1,171 @@
+package czds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSubmitChunkSize is the number of TLDs submitted per request by BatchSubmitRequest when BatchSubmitOptions.ChunkSize is not set
+const DefaultSubmitChunkSize = 50
+
+// BatchSubmitOptions configures BatchSubmitRequest and ResumeBatchSubmit
+type BatchSubmitOptions struct {
+	ChunkSize int // number of TLDs submitted per request, defaults to DefaultSubmitChunkSize
+	// Store persists which TLDs have been submitted after every chunk so a failed batch
+	// can be continued with ResumeBatchSubmit. Progress is not saved if nil
+	Store Cache
+	// Key is the key progress is saved under in Store, use a different key for each batch
+	Key string
+}
+
+// BatchSubmitResult describes the outcome of BatchSubmitRequest or ResumeBatchSubmit
+// the lists are sorted alphabetically by A-label
+type BatchSubmitResult struct {
+	Submitted []string // TLDs submitted by this call
+	Skipped   []string // TLDs skipped as they were already submitted by an earlier call with the same Store and Key
+}
+
+// batchProgress is the progress of a batch submission saved in BatchSubmitOptions.Store
+type batchProgress struct {
+	Submitted []string `json:"submitted"`
+}
+
+// BatchSubmitRequest submits request.TLDNames in chunks of opts.ChunkSize TLDs, each with the rest of request's fields
+// the TLDs are sorted and de-duplicated first so the chunks are the same across runs. If request.TcVersion is empty
+// the current terms and conditions are used. Any progress already saved in opts.Store under opts.Key is discarded.
+// If a chunk fails the TLDs submitted so far are returned with the error, use ResumeBatchSubmit to continue
+func (c *Client) BatchSubmitRequest(ctx context.Context, request *RequestSubmission, opts *BatchSubmitOptions) (*BatchSubmitResult, error) {
+	if opts == nil {
+		opts = &BatchSubmitOptions{}
+	}
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	if opts.Store != nil && opts.Key != "" {
+		err := saveBatchProgress(opts.Store, opts.Key, &batchProgress{Submitted: []string{}})
+		if err != nil {
+			return nil, fmt.Errorf("saving batch submission progress: %w", err)
+		}
+	}
+	return c.batchSubmit(ctx, request, opts, nil)
+}
+
+// ResumeBatchSubmit continues a BatchSubmitRequest that failed part way, skipping the TLDs
+// that opts.Store records as already submitted under opts.Key so they are not requested twice.
+// request and opts should be the same as the failed batch
+func (c *Client) ResumeBatchSubmit(ctx context.Context, request *RequestSubmission, opts *BatchSubmitOptions) (*BatchSubmitResult, error) {
+	if opts == nil || opts.Store == nil {
+		return nil, fmt.Errorf("resuming a batch submission requires a progress Store")
+	}
+	progress, err := loadBatchProgress(opts.Store, opts.Key)
+	if err != nil {
+		return nil, err
+	}
+	return c.batchSubmit(ctx, request, opts, progress.Submitted)
+}
+
+// batchSubmit submits the TLDs of request not in done in chunks, saving progress to opts.Store
+func (c *Client) batchSubmit(ctx context.Context, request *RequestSubmission, opts *BatchSubmitOptions, done []string) (*BatchSubmitResult, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	if opts.Store != nil && opts.Key == "" {
+		return nil, fmt.Errorf("a Key is required to save batch submission progress")
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize < 1 {
+		chunkSize = DefaultSubmitChunkSize
+	}
+
+	skip := make(map[string]bool, len(done))
+	for _, tld := range done {
+		skip[tld] = true
+	}
+	result := &BatchSubmitResult{
+		Submitted: make([]string, 0, len(request.TLDNames)),
+		Skipped:   make([]string, 0, len(done)),
+	}
+	seen := make(map[string]bool, len(request.TLDNames))
+	pending := make([]string, 0, len(request.TLDNames))
+	for _, tld := range request.TLDNames {
+		tld = strings.ToLower(tld)
+		if seen[tld] {
+			continue
+		}
+		seen[tld] = true
+		if skip[tld] {
+			result.Skipped = append(result.Skipped, tld)
+		} else {
+			pending = append(pending, tld)
+		}
+	}
+	sort.Strings(pending)
+	sort.Strings(result.Skipped)
+
+	tcVersion := request.TcVersion
+	if tcVersion == "" && len(pending) > 0 {
+		terms, err := c.currentTerms(ctx)
+		if err != nil {
+			return result, err
+		}
+		tcVersion = terms.Version
+	}
+
+	progress := batchProgress{Submitted: append([]string(nil), done...)}
+	for start := 0; start < len(pending); start += chunkSize {
+		end := start + chunkSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		chunk := &RequestSubmission{
+			TLDNames:         pending[start:end],
+			Reason:           request.Reason,
+			TcVersion:        tcVersion,
+			AdditionalFTPIps: request.AdditionalFTPIps,
+		}
+		err := c.submitAcceptingTerms(ctx, chunk)
+		if err != nil {
+			return result, fmt.Errorf("submitting TLDs %d to %d of %d: %w", start+1, end, len(pending), err)
+		}
+		// keep any terms accepted while submitting for the later chunks
+		tcVersion = chunk.TcVersion
+		result.Submitted = append(result.Submitted, chunk.TLDNames...)
+		if opts.Store != nil {
+			progress.Submitted = append(progress.Submitted, chunk.TLDNames...)
+			err = saveBatchProgress(opts.Store, opts.Key, &progress)
+			if err != nil {
+				return result, fmt.Errorf("saving batch submission progress: %w", err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// loadBatchProgress returns the batch progress saved in store under key, empty if none is saved
+func loadBatchProgress(store Cache, key string) (*batchProgress, error) {
+	progress := &batchProgress{}
+	value, ok, err := store.Get(key)
+	if err != nil || !ok {
+		return progress, err
+	}
+	err = json.Unmarshal(value, progress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch submission progress for %q: %w", key, err)
+	}
+	return progress, nil
+}
+
+// saveBatchProgress saves progress in store under key
+func saveBatchProgress(store Cache, key string, progress *batchProgress) error {
+	value, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return store.Set(key, value)
+}
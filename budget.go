@@ -0,0 +1,70 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// downloadBudget shares the time left until a batch's deadline between the downloads that have not started
+// each download is given an equal share of the remaining time for the rounds of parallel downloads left,
+// so time saved by fast zones is passed on to later ones and the batch finishes by the deadline
+type downloadBudget struct {
+	mu       sync.Mutex
+	deadline time.Time
+	pending  int // downloads not yet started
+	parallel int
+}
+
+// newDownloadBudget returns a budget for jobs downloads run parallel at once, or nil if deadline is zero
+func newDownloadBudget(deadline time.Time, jobs, parallel int) *downloadBudget {
+	if deadline.IsZero() {
+		return nil
+	}
+	return &downloadBudget{
+		deadline: deadline,
+		pending:  jobs,
+		parallel: parallel,
+	}
+}
+
+// start allots time to the next download, returning a context that times out once it is used
+// returns ErrDeadlineBudget if the deadline has already passed
+func (b *downloadBudget) start(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	b.mu.Lock()
+	remaining := time.Until(b.deadline)
+	rounds := (b.pending + b.parallel - 1) / b.parallel
+	if b.pending > 0 {
+		b.pending--
+	}
+	b.mu.Unlock()
+
+	if remaining <= 0 {
+		return nil, nil, fmt.Errorf("%w: deadline %s passed before starting", ErrDeadlineBudget, b.deadline.Format(time.RFC3339))
+	}
+	if rounds < 1 {
+		rounds = 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, remaining/time.Duration(rounds))
+	return ctx, cancel, nil
+}
+
+// downloadWithBudget downloads job with downloadLink within the time budget allots it, if budget is not nil
+// a download that runs out of time while the batch continues fails with ErrDeadlineBudget
+func (c *Client) downloadWithBudget(ctx context.Context, budget *downloadBudget, dir string, job zoneJob, opts *DownloadOptions) ZoneResult {
+	if budget == nil {
+		return c.downloadLink(ctx, dir, job, opts)
+	}
+	jobCtx, cancel, err := budget.start(ctx)
+	if err != nil {
+		return ZoneResult{TLD: TLDFromLink(job.link), URL: job.link, Err: err}
+	}
+	defer cancel()
+	result := c.downloadLink(jobCtx, dir, job, opts)
+	if errors.Is(result.Err, context.DeadlineExceeded) && ctx.Err() == nil {
+		result.Err = fmt.Errorf("%w: %s did not finish in its share of the time available: %w", ErrDeadlineBudget, result.TLD, result.Err)
+	}
+	return result
+}
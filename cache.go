@@ -0,0 +1,108 @@
+package czds
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// Cache stores small values by key for the Client, such as the validators of downloaded zones
+// implementations must be safe for concurrent use
+type Cache interface {
+	// Get returns the value stored for key, ok is false if there is none
+	Get(key string) (value []byte, ok bool, err error)
+	// Set stores value for key, replacing any existing value
+	Set(key string, value []byte) error
+}
+
+// MemoryCache is a Cache held in memory for the lifetime of the process
+type MemoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		values: make(map[string][]byte),
+	}
+}
+
+// Get implements Cache
+func (mc *MemoryCache) Get(key string) ([]byte, bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	value, ok := mc.values[key]
+	return value, ok, nil
+}
+
+// Set implements Cache
+func (mc *MemoryCache) Set(key string, value []byte) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// DefaultFileCacheSuffix is the suffix of the sidecar files written by NewFileCache()
+const DefaultFileCacheSuffix = ".validators"
+
+// FileCache is a Cache that stores each value in a sidecar file next to the file named by its key
+// the download manager uses zone paths as keys, so "zones/example.txt.gz" is stored at "zones/example.txt.gz.validators"
+type FileCache struct {
+	Suffix string // appended to keys to name the sidecar files
+}
+
+// NewFileCache returns a FileCache using DefaultFileCacheSuffix
+func NewFileCache() *FileCache {
+	return &FileCache{
+		Suffix: DefaultFileCacheSuffix,
+	}
+}
+
+// Get implements Cache
+func (fc *FileCache) Get(key string) ([]byte, bool, error) {
+	value, err := os.ReadFile(key + fc.Suffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements Cache
+func (fc *FileCache) Set(key string, value []byte) error {
+	return os.WriteFile(key+fc.Suffix, value, 0660)
+}
+
+// validators are the HTTP cache validators of a downloaded zone used to make conditional requests
+type validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// loadValidators returns the validators stored in cache for key, or nil if there are none
+func loadValidators(cache Cache, key string) (*validators, error) {
+	value, ok, err := cache.Get(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	v := new(validators)
+	err = json.Unmarshal(value, v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// storeValidators saves v in cache for key
+func storeValidators(cache Cache, key string, v *validators) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return cache.Set(key, value)
+}
@@ -0,0 +1,200 @@
+package czds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tldStatusCache holds the catalog used by CachedTLDStatus()
+type tldStatusCache struct {
+	mu      sync.Mutex
+	status  []TLDStatus
+	fetched time.Time
+	gen     uint64            // incremented on invalidation so in-flight refreshes are not stored
+	refresh *tldStatusRefresh // refresh currently in progress, if any
+}
+
+// tldStatusRefresh is a single GetTLDStatus call shared by all callers waiting on it
+type tldStatusRefresh struct {
+	done   chan struct{}
+	status []TLDStatus
+	err    error
+}
+
+// CachedTLDStatus returns the TLD catalog from GetTLDStatus(), serving a cached copy if it is newer than maxAge
+// concurrent callers needing a refresh share a single GetTLDStatus() request
+// the returned slice is a copy and safe to modify
+func (c *Client) CachedTLDStatus(maxAge time.Duration) ([]TLDStatus, error) {
+	cache := &c.tldCache
+	cache.mu.Lock()
+	if cache.status != nil && c.now().Sub(cache.fetched) <= maxAge {
+		status := copyTLDStatus(cache.status)
+		cache.mu.Unlock()
+		return status, nil
+	}
+	if refresh := cache.refresh; refresh != nil {
+		// another caller is already refreshing, wait for it
+		cache.mu.Unlock()
+		<-refresh.done
+		return copyTLDStatus(refresh.status), refresh.err
+	}
+	refresh := &tldStatusRefresh{done: make(chan struct{})}
+	cache.refresh = refresh
+	gen := cache.gen
+	cache.mu.Unlock()
+
+	refresh.status, refresh.err = c.GetTLDStatus()
+
+	cache.mu.Lock()
+	if refresh.err == nil && gen == cache.gen {
+		cache.status = refresh.status
+		cache.fetched = c.now()
+	}
+	cache.refresh = nil
+	cache.mu.Unlock()
+	close(refresh.done)
+
+	return copyTLDStatus(refresh.status), refresh.err
+}
+
+// InvalidateTLDStatusCache clears the catalog cached by CachedTLDStatus()
+// so the next call will request a fresh copy
+func (c *Client) InvalidateTLDStatusCache() {
+	c.tldCache.mu.Lock()
+	defer c.tldCache.mu.Unlock()
+	c.tldCache.status = nil
+	c.tldCache.gen++
+}
+
+func copyTLDStatus(status []TLDStatus) []TLDStatus {
+	if status == nil {
+		return nil
+	}
+	return append(make([]TLDStatus, 0, len(status)), status...)
+}
+
+// TLDStatusChange is a TLD whose entry differs between two catalogs compared by DiffTLDStatus()
+type TLDStatusChange struct {
+	TLD string
+	Old TLDStatus
+	New TLDStatus
+}
+
+// TLDStatusDiff holds the differences between two catalogs from DiffTLDStatus()
+// all lists are sorted alphabetically by A-label
+type TLDStatusDiff struct {
+	Added   []TLDStatus       // TLDs only in the new catalog, such as newly delegated TLDs
+	Removed []TLDStatus       // TLDs only in the old catalog
+	Changed []TLDStatusChange // TLDs in both catalogs whose status or SFTP flag changed
+}
+
+// DiffTLDStatus compares two catalogs from GetTLDStatus(), such as snapshots saved with WriteTLDStatus(),
+// and reports the TLDs added, removed, or whose status changed. TLDs are matched case-insensitively
+func DiffTLDStatus(oldTLDs, newTLDs []TLDStatus) *TLDStatusDiff {
+	oldByTLD := make(map[string]TLDStatus, len(oldTLDs))
+	for _, tld := range oldTLDs {
+		oldByTLD[strings.ToLower(tld.TLD)] = tld
+	}
+	newByTLD := make(map[string]TLDStatus, len(newTLDs))
+	for _, tld := range newTLDs {
+		newByTLD[strings.ToLower(tld.TLD)] = tld
+	}
+
+	diff := &TLDStatusDiff{
+		Added:   make([]TLDStatus, 0),
+		Removed: make([]TLDStatus, 0),
+		Changed: make([]TLDStatusChange, 0),
+	}
+	for key, newStatus := range newByTLD {
+		oldStatus, ok := oldByTLD[key]
+		if !ok {
+			diff.Added = append(diff.Added, newStatus)
+			continue
+		}
+		if !strings.EqualFold(oldStatus.CurrentStatus, newStatus.CurrentStatus) || oldStatus.SFTP != newStatus.SFTP {
+			diff.Changed = append(diff.Changed, TLDStatusChange{TLD: key, Old: oldStatus, New: newStatus})
+		}
+	}
+	for key, oldStatus := range oldByTLD {
+		if _, ok := newByTLD[key]; !ok {
+			diff.Removed = append(diff.Removed, oldStatus)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].TLD < diff.Added[j].TLD })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].TLD < diff.Removed[j].TLD })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].TLD < diff.Changed[j].TLD })
+	return diff
+}
+
+// DetectCatalogChanges compares the current catalog from GetTLDStatus() to the snapshot saved at baselinePath
+// and returns the TLDs added, removed, and whose status changed, see DiffTLDStatus(). Changed TLDs are returned
+// with their new status. The snapshot is CSV if baselinePath ends in ".csv" and JSON otherwise.
+// A missing baseline is treated as empty so every TLD is added on the first run.
+// If save is set the current catalog is written to baselinePath for the next run
+func (c *Client) DetectCatalogChanges(baselinePath string, save bool) (added, removed, changed []TLDStatus, err error) {
+	format := FormatJSON
+	if strings.EqualFold(filepath.Ext(baselinePath), ".csv") {
+		format = FormatCSV
+	}
+	var baseline []TLDStatus
+	file, err := os.Open(baselinePath)
+	if err == nil {
+		baseline, err = ReadTLDStatus(file, format)
+		file.Close()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("reading catalog baseline %s: %w", baselinePath, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil, err
+	}
+
+	current, err := c.GetTLDStatus()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	diff := DiffTLDStatus(baseline, current)
+	changed = make([]TLDStatus, 0, len(diff.Changed))
+	for _, change := range diff.Changed {
+		changed = append(changed, change.New)
+	}
+
+	if save {
+		err = writeFileAtomic(baselinePath, func(w io.Writer) error {
+			return WriteTLDStatus(w, format, current)
+		})
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("saving catalog baseline %s: %w", baselinePath, err)
+		}
+	}
+	return diff.Added, diff.Removed, changed, nil
+}
+
+// writeFileAtomic writes path with write by writing to a partial file and renaming it into place
+// so path is never left partially written
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	partialPath := path + PartialSuffix
+	file, err := os.Create(partialPath)
+	if err != nil {
+		return err
+	}
+	err = write(file)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partialPath, path)
+	}
+	if err != nil {
+		os.Remove(partialPath)
+	}
+	return err
+}
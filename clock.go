@@ -0,0 +1,49 @@
+package czds
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultClockSkewThreshold is how far the local clock may differ from the server's before the Client
+// logs a warning and corrects its token expiry checks for the difference
+const DefaultClockSkewThreshold = time.Minute
+
+// clockSkew holds the measured difference between the server's clock and the local clock
+type clockSkew struct {
+	skew   atomic.Int64 // server time minus local time, in nanoseconds
+	warned atomic.Bool
+}
+
+// observeServerDate measures the clock skew from the Date header of resp
+func (c *Client) observeServerDate(ctx context.Context, resp *http.Response) {
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	skew := serverTime.Sub(c.now())
+	if skew.Abs() < DefaultClockSkewThreshold {
+		// the Date header only has second precision so small differences are not meaningful
+		c.clock.skew.Store(0)
+		c.clock.warned.Store(false)
+		return
+	}
+	c.clock.skew.Store(int64(skew))
+	if !c.clock.warned.Swap(true) {
+		c.logf(ctx, "local clock differs from the server's by %s, correcting token expiry checks", skew.Round(time.Second))
+	}
+}
+
+// ClockSkew returns how far ahead the server's clock is of the local clock, negative if it is behind,
+// as measured from the Date header of the most recent response. Returns 0 if the skew is below
+// DefaultClockSkewThreshold or no response has been received yet
+func (c *Client) ClockSkew() time.Duration {
+	return time.Duration(c.clock.skew.Load())
+}
+
+// serverNow returns the current time on the server's clock, correcting the client's clock for the measured skew
+func (c *Client) serverNow() time.Time {
+	return c.now().Add(c.ClockSkew())
+}
@@ -106,6 +106,7 @@ func main() {
 	// wait for workers to finish
 	<-loadDone
 	work.Wait()
+	v("downloaded %d zones, transferred %d bytes", client.DownloadedZones(), client.DownloadedBytes())
 }
 
 func addLinks(downloads []string) {
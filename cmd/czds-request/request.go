@@ -56,6 +56,10 @@ func main() {
 	}
 
 	client = czds.NewClient(*username, *password)
+	if *verbose {
+		// includes the library's warnings about the request reason
+		client.Logger = log.Default()
+	}
 
 	// validate credentials
 	v("Authenticating to %s", client.AuthURL)
@@ -0,0 +1,51 @@
+package czds
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// WithMaxConcurrency bounds the number of API requests the Client has in flight at once to n,
+// shared by every operation including metadata requests, batch lookups, and zone downloads.
+// A request holds its slot until its response body is closed, so a zone download counts
+// against the limit for its whole transfer. The links streamed by GetZoneDownloadLinksStream() are the exception,
+// releasing their slot once the response headers arrive so the downloads started from them can run.
+// A value <= 0 disables the limit
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		c.slots = nil
+		if n > 0 {
+			c.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquireSlot waits for a free request slot under the client's concurrency limit, or until ctx is done
+// the returned func releases the slot and is safe to call more than once
+func (c *Client) acquireSlot(ctx context.Context) (func(), error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-c.slots })
+	}, nil
+}
+
+// releaseBody is a response body that releases its request slot when closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (rb *releaseBody) Close() error {
+	err := rb.ReadCloser.Close()
+	rb.release()
+	return err
+}
@@ -0,0 +1,46 @@
+package czds
+
+import (
+	"context"
+	"fmt"
+)
+
+// CredentialProvider supplies the credentials the Client authenticates with
+// Retrieve is called every time the client authenticates, including when its token expires,
+// so credentials rotated in a secret manager are picked up without restarting. Implementations must be safe for concurrent use
+type CredentialProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// Retrieve implements CredentialProvider by returning the static credentials unchanged
+func (creds Credentials) Retrieve(ctx context.Context) (Credentials, error) {
+	return creds, nil
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider
+type CredentialProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Retrieve implements CredentialProvider by calling f
+func (f CredentialProviderFunc) Retrieve(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// WithCredentialProvider authenticates with the credentials from provider instead of the static
+// username and password passed to NewClient()
+func WithCredentialProvider(provider CredentialProvider) Option {
+	return func(c *Client) {
+		c.CredentialProvider = provider
+	}
+}
+
+// credentials returns the credentials to authenticate with from the client's CredentialProvider, or Creds if not set
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	if c.CredentialProvider == nil {
+		return c.Creds, nil
+	}
+	creds, err := c.CredentialProvider.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("unable to retrieve credentials: %w", err)
+	}
+	return creds, nil
+}
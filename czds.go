@@ -2,11 +2,14 @@ package czds
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,6 +42,9 @@ var (
 			TLSHandshakeTimeout:   10 * time.Second,
 			ResponseHeaderTimeout: 10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+			},
 		},
 	}
 )
@@ -53,6 +59,104 @@ type Client struct {
 	authExp    time.Time
 	Creds      Credentials
 	authMutex  sync.Mutex
+
+	// CredentialProvider supplies the credentials to authenticate with each time the client authenticates
+	// Creds is used if nil, see WithCredentialProvider()
+	CredentialProvider CredentialProvider
+
+	// Logger receives log messages about the requests the Client makes, if set
+	Logger Logger
+
+	// AutoAcceptTerms allows the request helpers to accept updated terms and conditions
+	// and resubmit once when the terms change between fetching and submitting
+	AutoAcceptTerms bool
+
+	// ReadOnly makes every call that would change the account, such as submitting requests,
+	// fail with ErrReadOnly without contacting the API. Do() only allows GET and HEAD requests and
+	// known read-only POST endpoints. Use this for clients used for reporting or monitoring
+	ReadOnly bool
+
+	// CheckPrivateData makes the zone download methods look up the account's request for each zone first
+	// and fail with ErrPrivateDataError if it shows the account lacks rights to the zone's private data,
+	// rather than the download failing with an unclear server error. The details of every approved request
+	// are fetched to check and reused for PrivateDataTTL, so this costs extra API requests periodically
+	CheckPrivateData bool
+	// PrivateDataTTL is how long the private data checks of CheckPrivateData are reused for,
+	// defaults to DefaultPrivateDataTTL
+	PrivateDataTTL time.Duration
+
+	// MinReasonLength and MaxReasonLength bound the length of request reasons in characters, checked before submitting
+	// they default to DefaultMinReasonLength and DefaultMaxReasonLength, a negative MaxReasonLength disables the maximum
+	MinReasonLength int
+	MaxReasonLength int
+
+	// ReasonValidator checks request reasons for likely problems before submitting, logging any warnings
+	// it does not stop the submission. DefaultReasonValidator is used if nil
+	ReasonValidator ReasonValidator
+
+	// RetryPolicy controls how API requests, zone downloads and DownloadAllRequests() retry transient failures
+	// DefaultRetryPolicy is used if nil, requests that change the account are only retried with WithRetryOverride()
+	RetryPolicy *RetryPolicy
+
+	// Now returns the current time used by the time based helpers, such as expiration checks,
+	// catalog cache freshness, clock skew and token expiry. Defaults to time.Now if nil. Set this to pin the time in tests
+	Now func() time.Time
+
+	downloadLimiter  *tokenBucket
+	maxResponseBytes int64
+	downloadTimeout  time.Duration
+	emptyReport      *RetryPolicy // retries of empty requests reports, not retried if nil
+	clock            clockSkew
+	quota            quotaTracker
+	throttle         requestThrottle
+	slots            chan struct{} // bounds requests in flight, nil if unlimited
+	tldCache         tldStatusCache
+	privateData      privateDataCache
+	optionErr        error // error applying an Option, returned by all requests
+	captureRaw       bool
+	rawMutex         sync.Mutex
+	lastRaw          []byte
+}
+
+// Option sets optional configuration on a Client created by NewClient
+type Option func(*Client)
+
+// authPath is the path of the authentication endpoint on the ICANN account API
+const authPath = "/api/authenticate"
+
+// WithAuthBaseURL authenticates against the ICANN account API at baseURL, such as a staging identity server or mock,
+// independently of the CZDS API set with WithBaseURL(). The authentication path is appended to baseURL
+func WithAuthBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.AuthURL = strings.TrimSuffix(baseURL, "/") + authPath
+	}
+}
+
+// WithAuthURL sets the full URL of the authentication endpoint, see WithAuthBaseURL()
+func WithAuthURL(url string) Option {
+	return func(c *Client) {
+		c.AuthURL = url
+	}
+}
+
+// WithBaseURL sets the base URL of the CZDS API, such as TestBaseURL
+// this does not change where the Client authenticates, see WithAuthBaseURL()
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithDownloadBandwidth caps the combined throughput of all zone downloads made by the Client
+// to bytesPerSec. Concurrent downloads share the limit, as do readers from Client.RateLimitedReader().
+// A value <= 0 disables the limit
+func WithDownloadBandwidth(bytesPerSec int64) Option {
+	return func(c *Client) {
+		c.downloadLimiter = nil
+		if bytesPerSec > 0 {
+			c.downloadLimiter = newTokenBucket(float64(bytesPerSec))
+		}
+	}
 }
 
 // Credentials used by the czds.Client
@@ -67,7 +171,8 @@ type authResponse struct {
 }
 
 // NewClient returns a new instance of the CZDS Client with the default production URLs
-func NewClient(username, password string) *Client {
+// and any provided Options applied
+func NewClient(username, password string, opts ...Option) *Client {
 	client := &Client{
 		AuthURL: AuthURL,
 		BaseURL: BaseURL,
@@ -76,25 +181,36 @@ func NewClient(username, password string) *Client {
 			Password: password,
 		},
 	}
+	for _, opt := range opts {
+		opt(client)
+	}
 	return client
 }
 
 // this function does NOT make network requests if the auth is valid
-func (c *Client) checkAuth() error {
+func (c *Client) checkAuth(ctx context.Context) error {
 	// used a mutex to prevent multiple threads from authenticating at the same time
 	c.authMutex.Lock()
 	defer c.authMutex.Unlock()
 	if c.auth.AccessToken == "" {
 		// no token yet
-		return c.Authenticate()
+		return c.authenticate(ctx)
 	}
-	if time.Now().After(c.authExp) {
+	if c.serverNow().After(c.authExp) {
 		// token expired, renew
-		return c.Authenticate()
+		return c.authenticate(ctx)
 	}
 	return nil
 }
 
+// now returns the current time from the client's clock
+func (c *Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
 func (c *Client) httpClient() *http.Client {
 	if c.HTTPClient != nil {
 		return c.HTTPClient
@@ -102,16 +218,116 @@ func (c *Client) httpClient() *http.Client {
 	return httpClient
 }
 
+// requestConfig holds the per request settings applied by RequestOptions
+type requestConfig struct {
+	header    http.Header
+	allowAuth bool
+	// streamBody releases the request's concurrency slot once the response headers arrive instead of
+	// when the body is closed, for responses read while requests that need slots are started
+	streamBody bool
+	// noRetry makes a single attempt, for callers that retry the whole download themselves
+	noRetry bool
+}
+
+// RequestOption changes a single API request made with Do() without changing the Client
+type RequestOption func(*requestConfig)
+
+// WithRequestHeader sets the header key to value on the request, replacing the Client's value if it sets one
+// such as Accept. The Authorization header can only be set along with WithAuthorizationOverride()
+func WithRequestHeader(key, value string) RequestOption {
+	return func(rc *requestConfig) {
+		if rc.header == nil {
+			rc.header = make(http.Header)
+		}
+		rc.header.Set(key, value)
+	}
+}
+
+// WithAuthorizationOverride allows WithRequestHeader() to replace the Client's Authorization header for the request
+func WithAuthorizationOverride() RequestOption {
+	return func(rc *requestConfig) {
+		rc.allowAuth = true
+	}
+}
+
+// withStreamedBody sets requestConfig.streamBody for the request
+func withStreamedBody() RequestOption {
+	return func(rc *requestConfig) {
+		rc.streamBody = true
+	}
+}
+
+// withoutRetry sets requestConfig.noRetry for the request
+func withoutRetry() RequestOption {
+	return func(rc *requestConfig) {
+		rc.noRetry = true
+	}
+}
+
 // apiRequest makes a request to the client's API endpoint
-func (c *Client) apiRequest(auth bool, method, url string, request io.Reader) (*http.Response, error) {
+// a 304 Not Modified response is returned without error for conditional requests
+// and a 206 Partial Content response for range requests.
+// Failed requests are retried following apiRetryPolicy() if request can be rewound to send again
+func (c *Client) apiRequest(ctx context.Context, auth bool, method, url string, request io.Reader, opts ...RequestOption) (*http.Response, error) {
+	if c.optionErr != nil {
+		return nil, c.optionErr
+	}
+	var config requestConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if _, ok := config.header["Authorization"]; ok && !config.allowAuth {
+		return nil, fmt.Errorf("overriding the Authorization header requires WithAuthorizationOverride()")
+	}
+
+	policy := c.apiRetryPolicy(ctx, method, url)
+	// a body is only sent again if it can be seeked back to its start, http.Client closes bodies that are io.Closers
+	seeker, canSeek := request.(io.Seeker)
+	if _, closer := request.(io.Closer); closer {
+		canSeek = false
+	}
+	var start int64
+	if canSeek {
+		var err error
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		canSeek = err == nil
+	}
+	if config.noRetry || (request != nil && !canSeek) {
+		policy.MaxRetries = 0
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.apiRequestOnce(ctx, auth, method, url, request, &config)
+		if err == nil || !policy.shouldRetry(attempt, err) {
+			return resp, err
+		}
+		c.logf(ctx, "retrying %s %s after error: %s", method, url, err)
+		err = policy.wait(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if canSeek {
+			_, err = seeker.Seek(start, io.SeekStart)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// apiRequestOnce makes a single attempt of an apiRequest() with the request's config
+func (c *Client) apiRequestOnce(ctx context.Context, auth bool, method, url string, request io.Reader, config *requestConfig) (*http.Response, error) {
+	err := c.throttle.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if auth {
-		err := c.checkAuth()
+		err = c.checkAuth(ctx)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	req, err := http.NewRequest(method, url, request)
+	req, err := http.NewRequestWithContext(ctx, method, url, request)
 	if err != nil {
 		return nil, err
 	}
@@ -120,25 +336,111 @@ func (c *Client) apiRequest(auth bool, method, url string, request io.Reader) (*
 	}
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.auth.AccessToken))
+	for key, values := range config.header {
+		req.Header[key] = values
+	}
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.logf(ctx, "%s %s", method, url)
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
+		release()
+		c.logf(ctx, "%s %s failed: %s", method, url, err)
 		return nil, err
 	}
+	if config.streamBody {
+		release()
+	} else {
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	}
+	c.logf(ctx, "%s %s returned %s", method, url, resp.Status)
+	c.observeServerDate(ctx, resp)
 
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		return resp, nil
+	}
+	if resp.StatusCode == http.StatusPartialContent && req.Header.Get("Range") != "" {
+		return resp, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return resp, fmt.Errorf("Error on request %s, got Status %s %s", url, resp.Status, http.StatusText(resp.StatusCode))
+		defer resp.Body.Close()
+		return nil, newStatusError(url, resp)
 	}
 
 	return resp, nil
 }
 
 // jsonAPI performes an authenticated json API request
-func (c *Client) jsonAPI(method, path string, request, response interface{}) error {
-	return c.jsonRequest(true, method, c.BaseURL+path, request, response)
+func (c *Client) jsonAPI(ctx context.Context, method, path string, request, response interface{}, opts ...RequestOption) error {
+	return c.jsonRequest(ctx, true, method, c.BaseURL+path, request, response, opts...)
+}
+
+// Do performs an authenticated JSON API request to path on the client's BaseURL
+// request is encoded as the JSON body if not nil, and the response is decoded into response if not nil.
+// This allows calling API endpoints not otherwise implemented by this package.
+// opts can set headers on just this request, such as for tracing
+func (c *Client) Do(ctx context.Context, method, path string, request, response interface{}, opts ...RequestOption) error {
+	if c.ReadOnly && !readOnlyRequest(method, path) {
+		return fmt.Errorf("%w: %s %s", ErrReadOnly, method, path)
+	}
+	return c.jsonAPI(ctx, method, path, request, response, opts...)
+}
+
+// readOnlyPOSTPaths are API endpoints that use POST but do not change the account
+var readOnlyPOSTPaths = map[string]bool{
+	"/czds/requests/all": true,
+}
+
+// readOnlyRequest reports if a request with method to path is known not to change the account
+func readOnlyRequest(method, path string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return readOnlyPOSTPaths[path]
+	}
+	return false
+}
+
+// checkWritable returns ErrReadOnly if the client is read only
+func (c *Client) checkWritable() error {
+	if c.ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// DoInto performs an authenticated JSON API request like Client.Do() and returns the response decoded as T
+func DoInto[T any](ctx context.Context, c *Client, method, path string, request interface{}, opts ...RequestOption) (T, error) {
+	var response T
+	err := c.Do(ctx, method, path, request, &response, opts...)
+	return response, err
+}
+
+// DoRaw performs an authenticated API request to path on the client's BaseURL like Do() without encoding or decoding JSON
+// body is sent as the request body if not nil, and the response body is copied to w unchanged, which allows proxying
+// the API while reusing the client's authentication and rate limiting. The response headers are returned, such as
+// for forwarding Content-Type. Responses are not limited by WithMaxResponseBytes() so zones can also be passed through,
+// and like zone downloads they are read under WithDownloadBandwidth() and counted by DownloadedBytes().
+// Non 200 responses return a *StatusError without writing to w
+func (c *Client) DoRaw(ctx context.Context, method, path string, body io.Reader, w io.Writer, opts ...RequestOption) (http.Header, error) {
+	if c.ReadOnly && !readOnlyRequest(method, path) {
+		return nil, fmt.Errorf("%w: %s %s", ErrReadOnly, method, path)
+	}
+	resp, err := c.apiRequest(ctx, true, method, c.BaseURL+path, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, c.downloadReader(ctx, resp.Body))
+	return resp.Header, err
 }
 
 // jsonRequest performes a request to the API endpoint sending and receiving JSON objects
-func (c *Client) jsonRequest(auth bool, method, url string, request, response interface{}) error {
+func (c *Client) jsonRequest(ctx context.Context, auth bool, method, url string, request, response interface{}, opts ...RequestOption) error {
 	var payloadReader io.Reader
 	if request != nil {
 		jsonPayload, err := json.Marshal(request)
@@ -148,14 +450,27 @@ func (c *Client) jsonRequest(auth bool, method, url string, request, response in
 		payloadReader = bytes.NewReader(jsonPayload)
 	}
 
-	resp, err := c.apiRequest(auth, method, url, payloadReader)
+	resp, err := c.apiRequest(ctx, auth, method, url, payloadReader, opts...)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	var body io.Reader = c.limitResponse(resp.Body)
+	// authentication responses are never captured as they contain the access token
+	if c.captureRaw && auth {
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		c.rawMutex.Lock()
+		c.lastRaw = raw
+		c.rawMutex.Unlock()
+		body = bytes.NewReader(raw)
+	}
+
 	if response != nil {
-		err = json.NewDecoder(resp.Body).Decode(&response)
+		err = json.NewDecoder(body).Decode(&response)
 		if err != nil {
 			return err
 		}
@@ -164,25 +479,127 @@ func (c *Client) jsonRequest(auth bool, method, url string, request, response in
 	return nil
 }
 
+// DefaultMaxResponseBytes is the largest JSON API response body accepted unless changed with WithMaxResponseBytes()
+const DefaultMaxResponseBytes = 64 * 1024 * 1024
+
+// WithMaxResponseBytes limits the size of JSON API response bodies to n bytes, larger responses fail with ErrResponseTooLarge
+// zone downloads and the requests report are not limited. A value < 0 disables the limit
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// limitResponse wraps a JSON API response body in the client's response size limit
+func (c *Client) limitResponse(body io.Reader) io.Reader {
+	limit := c.maxResponseBytes
+	if limit == 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	if limit < 0 {
+		return body
+	}
+	return &maxBytesReader{r: body, remaining: limit}
+}
+
+// maxBytesReader reads from r returning ErrResponseTooLarge once more than remaining bytes are read
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// read one byte past the limit to detect bodies that exceed it
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n + int(m.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// WithRawResponseCapture keeps the raw body of the most recent JSON API response for LastRawResponse()
+// this is intended for debugging how responses are decoded and is disabled by default to avoid retaining memory
+func WithRawResponseCapture() Option {
+	return func(c *Client) {
+		c.captureRaw = true
+	}
+}
+
+// LastRawResponse returns a copy of the raw body of the most recent JSON API response
+// returns nil unless the Client was created with WithRawResponseCapture()
+func (c *Client) LastRawResponse() []byte {
+	c.rawMutex.Lock()
+	defer c.rawMutex.Unlock()
+	if c.lastRaw == nil {
+		return nil
+	}
+	return append([]byte(nil), c.lastRaw...)
+}
+
 // Authenticate tests the client's credentials and gets an authentication token from the server
 // calling this is optional. All other functions will check the auth state on their own first and authenticate if necessary.
 func (c *Client) Authenticate() error {
+	return c.authenticate(context.Background())
+}
 
+// authenticate gets a new authentication token from the server
+func (c *Client) authenticate(ctx context.Context) error {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return err
+	}
+	c.logf(ctx, "authenticating to %s", c.AuthURL)
 	authResp := authResponse{}
-	err := c.jsonRequest(false, "POST", c.AuthURL, c.Creds, &authResp)
+	err = c.jsonRequest(ctx, false, "POST", c.AuthURL, creds, &authResp)
 	if err != nil {
 		return err
 	}
 	c.auth = authResp
 	c.authExp, err = authResp.getExpiration()
 
-	if !c.authExp.After(time.Now()) {
+	if !c.authExp.After(c.serverNow()) {
 		return fmt.Errorf("Unable to authenticate")
 	}
 
 	return nil
 }
 
+// VerifyCredentials checks that the client's credentials are valid by authenticating with the server
+// returns ErrUnauthorized if the credentials are rejected. No other API requests are made
+func (c *Client) VerifyCredentials(ctx context.Context) error {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+	return c.authenticate(ctx)
+}
+
+// AccountIdentity returns the account the client is authenticated as, authenticating first with ctx if needed
+// this is the email claim from the authentication token, or the subject if no email is present.
+// The token's signature is not verified as it was received directly from the authentication server
+func (c *Client) AccountIdentity(ctx context.Context) (string, error) {
+	err := c.checkAuth(ctx)
+	if err != nil {
+		return "", err
+	}
+	token, err := jwt.DecodeJWT(c.auth.AccessToken)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode authentication token: %w", err)
+	}
+	if token.Data.Email != "" {
+		return token.Data.Email, nil
+	}
+	if token.Data.Sub != "" {
+		return token.Data.Sub, nil
+	}
+	return "", fmt.Errorf("authentication token does not contain an account identity")
+}
+
 // getExpiration returns the expiration of the authentication token
 func (ar *authResponse) getExpiration() (time.Time, error) {
 	token, err := jwt.DecodeJWT(ar.AccessToken)
@@ -0,0 +1,76 @@
+package czds
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testToken returns an unsigned authentication token that expires in an hour
+func testToken() string {
+	encode := base64.RawURLEncoding.EncodeToString
+	header := encode([]byte(`{"kid":"test","alg":"none"}`))
+	data := encode([]byte(fmt.Sprintf(`{"sub":"test@example.com","exp":%d}`, time.Now().Add(time.Hour).Unix())))
+	return header + "." + data + "." + encode([]byte("signature"))
+}
+
+// newTestClient returns a Client authenticating against and served by a test server
+// authentication is answered by the server, and every other request is passed to handler
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(authPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(authResponse{AccessToken: testToken()})
+	})
+	mux.Handle("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithAuthBaseURL(server.URL), WithBaseURL(server.URL)}, opts...)
+	return NewClient("user", "password", opts...)
+}
+
+func TestDoRawCountsBytes(t *testing.T) {
+	zone := []byte("example.\t86400\tin\tns\tns1.example.\n")
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zone)
+	}), WithDownloadBandwidth(1<<20))
+
+	var out bytes.Buffer
+	_, err := client.DoRaw(context.Background(), http.MethodGet, "/czds/downloads/example.zone", nil, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), zone) {
+		t.Errorf("got body %q, want %q", out.Bytes(), zone)
+	}
+	if got := client.DownloadedBytes(); got != int64(len(zone)) {
+		t.Errorf("DownloadedBytes() = %d, want %d", got, len(zone))
+	}
+}
+
+func TestAccountIdentity(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.AccountIdentity(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v with a canceled context, want context.Canceled", err)
+	}
+
+	identity, err := client.AccountIdentity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity != "test@example.com" {
+		t.Errorf("got identity %q, want test@example.com", identity)
+	}
+}
@@ -0,0 +1,136 @@
+// Package czdstest replays recorded CZDS API interactions through a czds.Client's HTTPClient
+// so code using the czds package can be exercised without live credentials
+package czdstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/lanrat/czds"
+)
+
+// Cassette holds recorded request and response pairs
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and the response the server returned for it
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a request by its method and full URL
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is the response to replay for a RecordedRequest
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// LoadCassette reads a Cassette from the JSON file at path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := new(Cassette)
+	err = json.Unmarshal(data, cassette)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Replayer is an http.RoundTripper that answers requests from a Cassette
+// interactions for the same method and URL are replayed in the order they were recorded
+// and requests with no remaining recorded interaction fail
+type Replayer struct {
+	mu      sync.Mutex
+	pending map[string][]RecordedResponse
+}
+
+// NewReplayer returns a Replayer serving the interactions in cassette
+func NewReplayer(cassette *Cassette) *Replayer {
+	r := &Replayer{
+		pending: make(map[string][]RecordedResponse),
+	}
+	for _, interaction := range cassette.Interactions {
+		key := interactionKey(interaction.Request.Method, interaction.Request.URL)
+		r.pending[key] = append(r.pending[key], interaction.Response)
+	}
+	return r
+}
+
+// NewClient returns a czds.Client using the test URLs that is served entirely by the interactions in cassette
+func NewClient(cassette *Cassette) *czds.Client {
+	client := czds.NewClient("replay", "replay")
+	client.AuthURL = czds.TestAuthURL
+	client.BaseURL = czds.TestBaseURL
+	client.HTTPClient = &http.Client{
+		Transport: NewReplayer(cassette),
+	}
+	return client
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := interactionKey(req.Method, req.URL.String())
+	r.mu.Lock()
+	responses := r.pending[key]
+	if len(responses) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded interaction for %s", key)
+	}
+	recorded := responses[0]
+	r.pending[key] = responses[1:]
+	r.mu.Unlock()
+
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+	if req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
+
+// Remaining returns the number of recorded interactions that have not been replayed yet
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int
+	for _, responses := range r.pending {
+		n += len(responses)
+	}
+	return n
+}
+
+func interactionKey(method, url string) string {
+	return method + " " + url
+}
@@ -0,0 +1,111 @@
+package czdstest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lanrat/czds"
+)
+
+// replayClient returns a client like NewClient() for the cassette at path and its Replayer
+func replayClient(t *testing.T, path string) (*czds.Client, *Replayer) {
+	t.Helper()
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(cassette)
+	replayer := NewReplayer(cassette)
+	client.HTTPClient = &http.Client{Transport: replayer}
+	return client, replayer
+}
+
+// checkReplayed fails t if any of replayer's interactions were not replayed
+func checkReplayed(t *testing.T, replayer *Replayer) {
+	t.Helper()
+	if n := replayer.Remaining(); n != 0 {
+		t.Errorf("%d recorded interactions were not replayed", n)
+	}
+}
+
+func TestReplayPagination(t *testing.T) {
+	client, replayer := replayClient(t, filepath.Join("testdata", "pagination.json"))
+
+	var tlds []string
+	for page := 0; page < 3; page++ {
+		requests, err := client.GetRequests(&czds.RequestsFilter{
+			Pagination: czds.RequestsPagination{Size: 2, Page: page},
+		})
+		if err != nil {
+			t.Fatalf("page %d: %s", page, err)
+		}
+		if requests.TotalRequests != 3 {
+			t.Errorf("page %d: got TotalRequests %d, want 3", page, requests.TotalRequests)
+		}
+		for _, request := range requests.Requests {
+			tlds = append(tlds, request.TLD)
+		}
+	}
+	want := []string{"example", "xn--d1acj3b", "test"}
+	if len(tlds) != len(want) {
+		t.Fatalf("got TLDs %q, want %q", tlds, want)
+	}
+	for i := range want {
+		if tlds[i] != want[i] {
+			t.Errorf("got TLDs %q, want %q", tlds, want)
+			break
+		}
+	}
+	checkReplayed(t, replayer)
+}
+
+func TestReplayTerms(t *testing.T) {
+	client, replayer := replayClient(t, filepath.Join("testdata", "terms.json"))
+
+	terms, err := client.GetTerms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if terms.Version != "2.0" {
+		t.Errorf("got terms version %q, want 2.0", terms.Version)
+	}
+	if terms.Content == "" {
+		t.Error("got empty terms content")
+	}
+	checkReplayed(t, replayer)
+}
+
+func TestReplayDownload(t *testing.T) {
+	client, replayer := replayClient(t, filepath.Join("testdata", "download.json"))
+
+	links, err := client.GetLinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 {
+		t.Fatalf("got links %q, want 1 link", links)
+	}
+	info, err := client.GetDownloadInfo(links[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Filename != "example.txt.gz" || info.ContentLength != 156 {
+		t.Errorf("got download info %+v", info)
+	}
+
+	path := filepath.Join(t.TempDir(), info.Filename)
+	err = client.DownloadZone(links[0], path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Size() != info.ContentLength {
+		t.Errorf("downloaded %d bytes, want %d", stat.Size(), info.ContentLength)
+	}
+	checkReplayed(t, replayer)
+}
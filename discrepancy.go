@@ -0,0 +1,128 @@
+package czds
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Kinds of Discrepancy found by AuditApprovedRequests()
+const (
+	// DiscrepancyNotInCatalog is an approved request for a TLD missing from the catalog
+	DiscrepancyNotInCatalog = "approved request for a TLD not in the catalog"
+	// DiscrepancyCatalogNotApproved is an approved request for a TLD the catalog does not show as approved
+	DiscrepancyCatalogNotApproved = "approved request but the catalog is not approved"
+	// DiscrepancyRequestNotApproved is a TLD the catalog shows as approved without an approved request
+	DiscrepancyRequestNotApproved = "catalog is approved but no request is approved"
+	// DiscrepancySFTPMismatch is an approved request whose SFTP flag differs from the catalog's
+	DiscrepancySFTPMismatch = "request and catalog disagree on SFTP delivery"
+)
+
+// Discrepancy is a disagreement between the account's requests and the TLD catalog found by AuditApprovedRequests()
+type Discrepancy struct {
+	TLD           string // A-label of the TLD, lower case
+	Kind          string // one of the Discrepancy* constants
+	RequestID     string // the approved request, or the active request for DiscrepancyRequestNotApproved, if any
+	RequestStatus string // status of the request identified by RequestID
+	CatalogStatus string // TLDStatus.CurrentStatus from the catalog, empty if not in the catalog
+	RequestSFTP   bool
+	CatalogSFTP   bool
+}
+
+// AuditApprovedRequests cross checks the account's approved requests against the catalog from GetTLDStatus()
+// reporting TLDs approved in one but not the other, and approved TLDs whose SFTP flags disagree.
+// These disagreements explain zones that fail to download despite appearing approved.
+// Discrepancies are sorted by TLD then kind
+func (c *Client) AuditApprovedRequests(ctx context.Context) ([]Discrepancy, error) {
+	status, err := c.getTLDStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(map[string]TLDStatus, len(status))
+	for _, tld := range status {
+		catalog[strings.ToLower(tld.TLD)] = tld
+	}
+	byTLD, err := c.groupRequestsByTLD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string][]Request, len(byTLD))
+	for tld, requests := range byTLD {
+		tld = strings.ToLower(tld)
+		groups[tld] = append(groups[tld], requests...)
+	}
+
+	discrepancies := make([]Discrepancy, 0)
+	approved := make(map[string]bool, len(groups))
+	for tld, requests := range groups {
+		request, ok := latestApproved(requests)
+		if !ok {
+			continue
+		}
+		approved[tld] = true
+		entry, inCatalog := catalog[tld]
+		d := Discrepancy{
+			TLD:           tld,
+			RequestID:     request.RequestID,
+			RequestStatus: request.Status,
+			CatalogStatus: entry.CurrentStatus,
+			RequestSFTP:   request.SFTP,
+			CatalogSFTP:   entry.SFTP,
+		}
+		switch {
+		case !inCatalog:
+			d.Kind = DiscrepancyNotInCatalog
+			discrepancies = append(discrepancies, d)
+			continue
+		case !strings.EqualFold(entry.CurrentStatus, StatusApproved):
+			d.Kind = DiscrepancyCatalogNotApproved
+			discrepancies = append(discrepancies, d)
+		}
+		if request.SFTP != entry.SFTP {
+			d.Kind = DiscrepancySFTPMismatch
+			discrepancies = append(discrepancies, d)
+		}
+	}
+
+	for tld, entry := range catalog {
+		if approved[tld] || !strings.EqualFold(entry.CurrentStatus, StatusApproved) {
+			continue
+		}
+		d := Discrepancy{
+			TLD:           tld,
+			Kind:          DiscrepancyRequestNotApproved,
+			CatalogStatus: entry.CurrentStatus,
+			CatalogSFTP:   entry.SFTP,
+		}
+		if active, ok := ActiveRequest(groups[tld]); ok {
+			d.RequestID = active.RequestID
+			d.RequestStatus = active.Status
+			d.RequestSFTP = active.SFTP
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].TLD != discrepancies[j].TLD {
+			return discrepancies[i].TLD < discrepancies[j].TLD
+		}
+		return discrepancies[i].Kind < discrepancies[j].Kind
+	})
+	return discrepancies, nil
+}
+
+// latestApproved returns the most recently updated approved request in reqs
+func latestApproved(reqs []Request) (Request, bool) {
+	var latest Request
+	var found bool
+	for _, request := range reqs {
+		if !strings.EqualFold(request.Status, RequestApproved) {
+			continue
+		}
+		if !found || request.LastUpdated.After(latest.LastUpdated) {
+			latest = request
+			found = true
+		}
+	}
+	return latest, found
+}
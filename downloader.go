@@ -0,0 +1,669 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDownloadParallel is the number of zones downloaded at once by DownloadZones
+// when DownloadOptions.Parallel is not set
+const DefaultDownloadParallel = 5
+
+// NameFunc returns the local filename to save the zone for tld as
+// resp is the response to the zone's download request, or to its HEAD request when one was already made,
+// such as for DownloadOptions.Cache or a schedule. Its body must not be read
+type NameFunc func(tld string, resp *http.Response) string
+
+// DownloadOptions configures the zone download manager used by DownloadZones
+type DownloadOptions struct {
+	Parallel int      // number of zones to download at once, defaults to DefaultDownloadParallel
+	NameFunc NameFunc // filename to save each zone as, defaults to DefaultNameFunc
+	// FailFast stops all downloads on the first error and returns it,
+	// otherwise all zones are attempted and every error is returned joined together
+	FailFast bool
+	// AbortAfterFailures cancels the remaining downloads once this many zones have failed,
+	// returning ErrTooManyFailures with the results so far. 0 never aborts
+	AbortAfterFailures int
+	// Cache stores the ETag and Last-Modified validators of downloaded zones by path
+	// when set, zones already on disk are requested conditionally and skipped if unchanged.
+	// NewFileCache() stores them in sidecar files next to the zones
+	Cache Cache
+	// Required lists TLDs that must download successfully. When set, only failures of these TLDs
+	// are returned as an error, wrapping ErrRequiredZoneFailed, while the other zones are still attempted
+	// and their failures reported only in their results. A required TLD that is not approved also fails
+	Required []string
+	// SortBySize schedules the largest zones first to balance the parallel downloads,
+	// using the sizes from a HEAD request to every zone before any downloads start
+	SortBySize bool
+	// Less schedules zones in a custom order, returning true if a should be downloaded before b
+	// like SortBySize, every zone's metadata is requested before downloads start. Takes precedence over SortBySize
+	Less func(a, b ZoneMeta) bool
+	// Deadline is when the whole batch must finish by, the zero time has no deadline. Each download is given
+	// an equal share of the time remaining when it starts, so timeouts shorten as the deadline approaches.
+	// Zones that run out of time or start after the deadline fail with ErrDeadlineBudget while the rest continue
+	Deadline time.Time
+}
+
+// ZoneMeta describes a zone from the HEAD request to its download link
+// fields the server did not provide are left zero, except ContentLength which is -1 if unknown
+type ZoneMeta struct {
+	TLD string
+	URL string
+	DownloadInfo
+}
+
+// ZoneResult holds the outcome of downloading a single zone with DownloadZones
+type ZoneResult struct {
+	TLD  string
+	URL  string
+	Path string // local path the zone was saved to
+	// NotModified is set when the local copy was up to date and the download was skipped, see DownloadOptions.Cache
+	NotModified  bool
+	Size         int64     // bytes saved to Path, or the size of the existing local copy when NotModified
+	SHA256       string    // hex encoded SHA-256 of the downloaded zone, empty when NotModified
+	LastModified time.Time // Last-Modified time of the zone reported by the server, the zero time if not provided
+	DownloadedAt time.Time // when the download completed or was found to be unchanged
+	Err          error
+}
+
+// DefaultNameFunc names zones with the filename suggested by the server in the
+// Content-Disposition header, falling back to "<tld>.zone" if none is provided
+func DefaultNameFunc(tld string, resp *http.Response) string {
+	if resp != nil {
+		_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+		if err == nil && params["filename"] != "" {
+			return params["filename"]
+		}
+	}
+	return tld + ".zone"
+}
+
+// zoneMetaFromResponse returns the ZoneMeta for link from the response to its HEAD request
+func zoneMetaFromResponse(link string, resp *http.Response) ZoneMeta {
+	meta := ZoneMeta{
+		TLD: TLDFromLink(link),
+		URL: link,
+	}
+	meta.ContentLength = -1
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err == nil {
+		meta.ContentLength = size
+	}
+	lastModified, err := time.Parse(time.RFC1123, resp.Header.Get("Last-Modified"))
+	if err == nil {
+		meta.LastModified = lastModified
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	if err == nil {
+		meta.Filename = params["filename"]
+	}
+	return meta
+}
+
+// largestFirst orders zones by descending size with unknown sizes last
+func largestFirst(a, b ZoneMeta) bool {
+	return a.ContentLength > b.ContentLength
+}
+
+// TLDFromLink returns the TLD for a zone download link returned by GetLinks()
+// ex: "https://czds-api.icann.org/czds/downloads/example.zone" returns "example"
+func TLDFromLink(link string) string {
+	return strings.TrimSuffix(path.Base(link), ".zone")
+}
+
+// DownloadZones downloads every zone available to the authenticated user into dir
+// downloads run in parallel and one ZoneResult is returned for each zone in the order they were scheduled,
+// which is the order returned by GetLinks() unless a schedule is set in opts.
+// Transient failures of each zone are retried following the client's RetryPolicy, see WithRetryOverride().
+// By default a zone failing to download does not stop the others, see DownloadOptions.FailFast.
+// If ctx is done no further downloads are started, in-flight downloads are cancelled,
+// and ctx.Err() is returned along with the partial results.
+// Returns ErrNoApprovedTLDs if there are no zones to download
+func (c *Client) DownloadZones(ctx context.Context, dir string, opts *DownloadOptions) ([]ZoneResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	links, err := c.getLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, ErrNoApprovedTLDs
+	}
+	results, err := c.downloadLinks(ctx, dir, links, opts)
+	if missingErr := missingRequired(links, opts); missingErr != nil {
+		err = errors.Join(missingErr, err)
+	}
+	return results, err
+}
+
+// ChangedZonesResult holds the outcome of DownloadChangedZones
+type ChangedZonesResult struct {
+	Updated []string     // TLDs that changed and were downloaded successfully, sorted alphabetically
+	Results []ZoneResult // results of every zone that was downloaded or attempted
+	// LastModified is the newest Last-Modified time of the zones that are up to date locally, or since if none are newer
+	// persist it and use it as since for the next run. It is kept before the Last-Modified time of any zone
+	// that failed to download, so the next run tries that zone again
+	LastModified time.Time
+}
+
+// DownloadChangedZones downloads the approved zones with a Last-Modified time after since into dir with the download manager
+// every zone is checked with a HEAD request first and unchanged zones are skipped. Zones whose HEAD request fails
+// or that have no Last-Modified time are downloaded so their errors or changes are not missed.
+// The changed zones are scheduled and their errors returned following opts like DownloadZones
+func (c *Client) DownloadChangedZones(ctx context.Context, dir string, since time.Time, opts *DownloadOptions) (*ChangedZonesResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = DefaultDownloadParallel
+	}
+	links, err := c.getLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, ErrNoApprovedTLDs
+	}
+
+	jobs, err := c.zoneJobs(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	metas := make([]*ZoneMeta, len(links))
+	forEachParallel(ctx, len(links), parallel, func(n int) {
+		if jobs[n].err != nil {
+			return
+		}
+		resp, err := c.apiRequest(ctx, true, "HEAD", links[n], nil)
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+		jobs[n].head = resp
+		meta := zoneMetaFromResponse(links[n], resp)
+		metas[n] = &meta
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ChangedZonesResult{
+		Updated:      make([]string, 0),
+		LastModified: since,
+	}
+	// the watermark only moves past zones that are up to date locally
+	var watermark, firstFailed time.Time
+	var failedUnknown bool
+	advance := func(lastModified time.Time) {
+		if lastModified.After(watermark) {
+			watermark = lastModified
+		}
+	}
+	changed := make([]zoneJob, 0, len(jobs))
+	for n, meta := range metas {
+		if meta != nil && !meta.LastModified.IsZero() && !meta.LastModified.After(since) {
+			advance(meta.LastModified)
+			continue
+		}
+		changed = append(changed, jobs[n])
+	}
+	less := opts.Less
+	if less == nil && opts.SortBySize {
+		less = largestFirst
+	}
+	if less != nil {
+		changed = c.scheduleJobs(ctx, changed, parallel, less)
+	}
+
+	result.Results, err = c.downloadJobs(ctx, dir, changed, parallel, opts)
+	for n, zone := range result.Results {
+		lastModified := zone.LastModified
+		if lastModified.IsZero() && changed[n].head != nil {
+			lastModified, _ = http.ParseTime(changed[n].head.Header.Get("Last-Modified"))
+		}
+		switch {
+		case zone.Err == nil:
+			advance(lastModified)
+			if !zone.NotModified {
+				result.Updated = append(result.Updated, zone.TLD)
+			}
+		case lastModified.IsZero():
+			failedUnknown = true
+		case firstFailed.IsZero() || lastModified.Before(firstFailed):
+			firstFailed = lastModified
+		}
+	}
+	switch {
+	case failedUnknown:
+		// a failed zone with an unknown Last-Modified time could be at any time after since
+	case !firstFailed.IsZero() && !watermark.Before(firstFailed):
+		if before := firstFailed.Add(-time.Second); before.After(since) {
+			result.LastModified = before
+		}
+	case watermark.After(since):
+		result.LastModified = watermark
+	}
+	sort.Strings(result.Updated)
+	if missingErr := missingRequired(links, opts); missingErr != nil {
+		err = errors.Join(missingErr, err)
+	}
+	return result, err
+}
+
+// EstimateMirrorSize makes a HEAD request to every approved zone, DefaultDownloadParallel at once, and returns
+// the total size in bytes of all the zones along with the size of each keyed by TLD. Zones whose size the server
+// does not report are -1 in perTLD and not counted in the total. If any HEAD request fails the first error is
+// returned along with the sizes that were found
+func (c *Client) EstimateMirrorSize(ctx context.Context) (total int64, perTLD map[string]int64, err error) {
+	links, err := c.getLinks(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(links) == 0 {
+		return 0, nil, ErrNoApprovedTLDs
+	}
+
+	metas := make([]ZoneMeta, len(links))
+	errs := make([]error, len(links))
+	forEachParallel(ctx, len(links), DefaultDownloadParallel, func(n int) {
+		resp, err := c.apiRequest(ctx, true, "HEAD", links[n], nil)
+		if err != nil {
+			errs[n] = err
+			return
+		}
+		resp.Body.Close()
+		metas[n] = zoneMetaFromResponse(links[n], resp)
+	})
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	perTLD = make(map[string]int64, len(links))
+	for n, meta := range metas {
+		if errs[n] != nil {
+			if err == nil {
+				err = errs[n]
+			}
+			continue
+		}
+		perTLD[meta.TLD] = meta.ContentLength
+		if meta.ContentLength > 0 {
+			total += meta.ContentLength
+		}
+	}
+	return total, perTLD, err
+}
+
+// zoneJob is a single zone for the download manager to download
+type zoneJob struct {
+	link string
+	head *http.Response // response to the link's HEAD request, if already made
+	err  error          // error to fail the job with before downloading, such as ErrPrivateDataError
+}
+
+// zoneJobs creates the download manager's jobs for links
+// when Client.CheckPrivateData is set jobs for zones with a private data error are set to fail
+func (c *Client) zoneJobs(ctx context.Context, links []string) ([]zoneJob, error) {
+	var privateDataErrs map[string]error
+	if c.CheckPrivateData {
+		var err error
+		privateDataErrs, err = c.privateDataErrors(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	jobs := make([]zoneJob, len(links))
+	for n, link := range links {
+		jobs[n].link = link
+		jobs[n].err = privateDataErrs[strings.ToLower(TLDFromLink(link))]
+	}
+	return jobs, nil
+}
+
+// downloadLinks runs the download manager over the provided zone links
+// if opts sets a schedule the links are reordered and results are returned in the scheduled order
+func (c *Client) downloadLinks(ctx context.Context, dir string, links []string, opts *DownloadOptions) ([]ZoneResult, error) {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = DefaultDownloadParallel
+	}
+
+	jobs, err := c.zoneJobs(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	less := opts.Less
+	if less == nil && opts.SortBySize {
+		less = largestFirst
+	}
+	if less != nil {
+		jobs = c.scheduleJobs(ctx, jobs, parallel, less)
+	}
+	return c.downloadJobs(ctx, dir, jobs, parallel, opts)
+}
+
+// scheduleJobs makes the HEAD request for every job that has not made it yet and sorts them with less
+// jobs whose HEAD request fails are scheduled last and retry it when downloaded
+func (c *Client) scheduleJobs(ctx context.Context, jobs []zoneJob, parallel int, less func(a, b ZoneMeta) bool) []zoneJob {
+	metas := make([]*ZoneMeta, len(jobs))
+	forEachParallel(ctx, len(jobs), parallel, func(n int) {
+		if jobs[n].err != nil {
+			return
+		}
+		resp := jobs[n].head
+		if resp == nil {
+			var err error
+			resp, err = c.apiRequest(ctx, true, "HEAD", jobs[n].link, nil)
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			jobs[n].head = resp
+		}
+		meta := zoneMetaFromResponse(jobs[n].link, resp)
+		metas[n] = &meta
+	})
+
+	order := make([]int, len(jobs))
+	for n := range order {
+		order[n] = n
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := metas[order[i]], metas[order[j]]
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return less(*a, *b)
+	})
+	sorted := make([]zoneJob, len(jobs))
+	for n, i := range order {
+		sorted[n] = jobs[i]
+	}
+	return sorted
+}
+
+// downloadJobs downloads jobs in order with up to parallel downloads at once
+func (c *Client) downloadJobs(ctx context.Context, dir string, jobs []zoneJob, parallel int, opts *DownloadOptions) ([]ZoneResult, error) {
+
+	// cancelled to stop in-flight downloads under FailFast
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	failures := newFailureTracker(opts, cancel)
+
+	budget := newDownloadBudget(opts.Deadline, len(jobs), parallel)
+	results := make([]ZoneResult, len(jobs))
+	started := make([]bool, len(jobs))
+	forEachParallel(ctx, len(jobs), parallel, func(n int) {
+		started[n] = true
+		results[n] = c.downloadWithBudget(ctx, budget, dir, jobs[n], opts)
+		failures.record(ctx, results[n].Err)
+	})
+	for n, job := range jobs {
+		if !started[n] {
+			results[n] = ZoneResult{
+				TLD: TLDFromLink(job.link),
+				URL: job.link,
+				Err: ctx.Err(),
+			}
+		}
+	}
+
+	if err := parentCtx.Err(); err != nil {
+		return results, err
+	}
+	if err := failures.err(); err != nil {
+		return results, err
+	}
+	return results, resultsError(results, opts)
+}
+
+// failureTracker stops a batch of downloads following DownloadOptions.FailFast and AbortAfterFailures
+// it is safe for concurrent use
+type failureTracker struct {
+	opts   *DownloadOptions
+	cancel context.CancelFunc // cancels the batch
+
+	mu       sync.Mutex
+	firstErr error // the failure that stopped the batch under FailFast
+	failures int
+	tooMany  bool // the batch was stopped by AbortAfterFailures
+}
+
+// newFailureTracker returns a failureTracker for opts that stops the batch with cancel
+func newFailureTracker(opts *DownloadOptions, cancel context.CancelFunc) *failureTracker {
+	return &failureTracker{
+		opts:   opts,
+		cancel: cancel,
+	}
+}
+
+// record counts a download in the batch run with ctx that failed with err, if err is not nil
+func (ft *failureTracker) record(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	// failures caused by the batch being cancelled are not counted
+	if ctx.Err() != nil {
+		return
+	}
+	ft.failures++
+	if ft.opts.FailFast && ft.firstErr == nil {
+		ft.firstErr = err
+		ft.cancel()
+	}
+	if ft.opts.AbortAfterFailures > 0 && ft.failures >= ft.opts.AbortAfterFailures && !ft.tooMany {
+		ft.tooMany = true
+		ft.cancel()
+	}
+}
+
+// err returns the error the batch was stopped for, or nil if it was not stopped
+func (ft *failureTracker) err() error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if ft.firstErr != nil {
+		return ft.firstErr
+	}
+	if ft.tooMany {
+		return fmt.Errorf("%w: aborted after %d zone downloads failed", ErrTooManyFailures, ft.failures)
+	}
+	return nil
+}
+
+// resultsError returns the error for a completed batch of results
+// this is every failure joined together, or only the failures of required TLDs when opts.Required is set
+func resultsError(results []ZoneResult, opts *DownloadOptions) error {
+	if len(opts.Required) == 0 {
+		return joinResultErrors(results)
+	}
+	required := requiredTLDs(opts.Required)
+	errs := make([]error, 0)
+	for _, result := range results {
+		if result.Err != nil && required[strings.ToLower(result.TLD)] {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrRequiredZoneFailed, result.TLD, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// requiredTLDs returns the set of required TLDs, lower case
+func requiredTLDs(tlds []string) map[string]bool {
+	required := make(map[string]bool, len(tlds))
+	for _, tld := range tlds {
+		required[strings.ToLower(strings.TrimSuffix(tld, "."))] = true
+	}
+	return required
+}
+
+// missingRequired returns ErrRequiredZoneFailed for every required TLD that is not one of links
+func missingRequired(links []string, opts *DownloadOptions) error {
+	if len(opts.Required) == 0 {
+		return nil
+	}
+	required := requiredTLDs(opts.Required)
+	for _, link := range links {
+		delete(required, strings.ToLower(TLDFromLink(link)))
+	}
+	missing := make([]string, 0, len(required))
+	for tld := range required {
+		missing = append(missing, tld)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("%w: not approved to download %s", ErrRequiredZoneFailed, strings.Join(missing, ", "))
+}
+
+// joinResultErrors returns the errors of all failed results joined together, or nil if none failed
+func joinResultErrors(results []ZoneResult) error {
+	errs := make([]error, 0)
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d zone downloads failed: %w", len(errs), len(results), errors.Join(errs...))
+	}
+	return nil
+}
+
+// downloadLink names and downloads a single zone for the download manager
+func (c *Client) downloadLink(ctx context.Context, dir string, job zoneJob, opts *DownloadOptions) ZoneResult {
+	link := job.link
+	result := ZoneResult{
+		TLD: TLDFromLink(link),
+		URL: link,
+	}
+	if job.err != nil {
+		result.Err = job.err
+		return result
+	}
+
+	nameFunc := opts.NameFunc
+	if nameFunc == nil {
+		nameFunc = DefaultNameFunc
+	}
+	// zonePath returns the path to save the zone as for a response to its download link
+	zonePath := func(resp *http.Response) (string, error) {
+		// only allow names inside dir
+		name := filepath.Base(nameFunc(result.TLD, resp))
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return "", fmt.Errorf("invalid filename for zone %s", result.TLD)
+		}
+		return filepath.Join(dir, name), nil
+	}
+
+	// conditional requests need the path of the local copy before downloading,
+	// otherwise the zone is named from the headers of the download itself
+	resp := job.head
+	if resp == nil && opts.Cache != nil {
+		var err error
+		resp, err = c.apiRequest(ctx, true, "HEAD", link, nil)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		resp.Body.Close()
+	}
+	destination := zonePath
+	var conditions []RequestOption
+	if resp != nil {
+		result.Path, result.Err = zonePath(resp)
+		if result.Err != nil {
+			return result
+		}
+		destination = func(*http.Response) (string, error) {
+			return result.Path, nil
+		}
+	}
+	if opts.Cache != nil {
+		conditions, result.Err = conditionalOptions(opts.Cache, result.Path)
+		if result.Err != nil {
+			return result
+		}
+	}
+	download, err := c.downloadZoneRetry(ctx, link, destination, conditions...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if download.path != "" {
+		result.Path = download.path
+	}
+	result.DownloadedAt = c.now()
+	result.LastModified, _ = http.ParseTime(download.header.Get("Last-Modified"))
+	if result.LastModified.IsZero() && resp != nil {
+		result.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	}
+	if download.notModified {
+		result.NotModified = true
+		if stat, err := os.Stat(result.Path); err == nil {
+			result.Size = stat.Size()
+		}
+		return result
+	}
+	result.Size = download.size
+	result.SHA256 = download.sha256
+	if opts.Cache != nil {
+		result.Err = storeValidators(opts.Cache, result.Path, &validators{
+			ETag:         download.header.Get("ETag"),
+			LastModified: download.header.Get("Last-Modified"),
+		})
+	}
+	return result
+}
+
+// downloadZoneRetry downloads the zone at link to destination retrying transient failures following the client's RetryPolicy
+// the zone is downloaded to a partial file so each attempt starts again cleanly
+func (c *Client) downloadZoneRetry(ctx context.Context, link string, destination func(*http.Response) (string, error), opts ...RequestOption) (*zoneDownload, error) {
+	policy := c.retryPolicy(ctx)
+	for attempt := 0; ; attempt++ {
+		download, err := c.downloadZoneTo(ctx, link, destination, append(opts, withoutRetry())...)
+		if err == nil || !policy.shouldRetry(attempt, err) {
+			return download, err
+		}
+		c.logf(ctx, "retrying download of %s after error: %s", link, err)
+		err = policy.wait(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// conditionalOptions returns the conditional request headers for the zone at path from its validators in cache
+// no conditions are returned if there is no local copy of the zone to keep
+func conditionalOptions(cache Cache, path string) ([]RequestOption, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	var conditions []RequestOption
+	v, err := loadValidators(cache, path)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil && v.ETag != "" {
+		conditions = append(conditions, WithRequestHeader("If-None-Match", v.ETag))
+	}
+	if v != nil && v.LastModified != "" {
+		conditions = append(conditions, WithRequestHeader("If-Modified-Since", v.LastModified))
+	}
+	return conditions, nil
+}
@@ -0,0 +1,208 @@
+package czds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowHandler serves download links to zones that never finish downloading before the client gives up
+func slowHandler(t *testing.T, tlds []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/czds/downloads/links" {
+			links := make([]string, len(tlds))
+			for n, tld := range tlds {
+				links[n] = "http://" + r.Host + "/czds/downloads/" + tld + ".zone"
+			}
+			json.NewEncoder(w).Encode(links)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/czds/downloads/") {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+			t.Errorf("request to %s was not cancelled", r.URL.Path)
+		}
+	})
+}
+
+func TestDownloadZonesDeadline(t *testing.T) {
+	tlds := []string{"example", "test", "invalid", "localhost"}
+	client := newTestClient(t, slowHandler(t, tlds))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, err := client.DownloadZones(ctx, t.TempDir(), &DownloadOptions{Parallel: 1})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("DownloadZones took %s to return after the deadline", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(results) != len(tlds) {
+		t.Fatalf("got %d results, want %d", len(results), len(tlds))
+	}
+	for n, result := range results {
+		if result.TLD != tlds[n] {
+			t.Errorf("result %d: got TLD %q, want %q", n, result.TLD, tlds[n])
+		}
+		if result.Err == nil {
+			t.Errorf("result %d: %s has no error", n, result.TLD)
+		}
+	}
+	// only the first zone started with one download at a time
+	for _, result := range results[1:] {
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Errorf("%s never started but has error %v", result.TLD, result.Err)
+		}
+	}
+}
+
+// zoneHandler serves download links to small zones named by Content-Disposition and counts the HEAD requests made
+func zoneHandler(tlds []string, heads *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/czds/downloads/links" {
+			links := make([]string, len(tlds))
+			for n, tld := range tlds {
+				links[n] = "http://" + r.Host + "/czds/downloads/" + tld + ".zone"
+			}
+			json.NewEncoder(w).Encode(links)
+			return
+		}
+		tld := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/czds/downloads/"), ".zone")
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		}
+		w.Header().Set("Content-Disposition", "attachment;filename="+tld+".txt.gz")
+		w.Header().Set("Last-Modified", "Wed, 30 Jan 2019 08:00:00 GMT")
+		w.Write([]byte(tld + ".\t86400\tin\tns\tns1." + tld + ".\n"))
+	})
+}
+
+func TestDownloadZonesNamesFromDownload(t *testing.T) {
+	tlds := []string{"example", "test"}
+	var heads atomic.Int32
+	client := newTestClient(t, zoneHandler(tlds, &heads))
+	dir := t.TempDir()
+
+	results, err := client.DownloadZones(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := heads.Load(); n != 0 {
+		t.Errorf("made %d HEAD requests, want 0", n)
+	}
+	for n, result := range results {
+		want := filepath.Join(dir, tlds[n]+".txt.gz")
+		if result.Path != want {
+			t.Errorf("%s: saved to %q, want %q", result.TLD, result.Path, want)
+		}
+		if _, err := os.Stat(want); err != nil {
+			t.Error(err)
+		}
+		if result.LastModified.IsZero() {
+			t.Errorf("%s: LastModified not set", result.TLD)
+		}
+	}
+	partials, _ := filepath.Glob(filepath.Join(dir, "*"+PartialSuffix))
+	if len(partials) != 0 {
+		t.Errorf("partial files left behind: %q", partials)
+	}
+}
+
+// changedZonesHandler serves zones with the Last-Modified times in modified, failing the downloads of the TLDs in broken
+func changedZonesHandler(modified map[string]time.Time, broken map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/czds/downloads/links" {
+			links := make([]string, 0, len(modified))
+			for _, tld := range []string{"alpha", "bravo", "charlie"} {
+				if _, ok := modified[tld]; ok {
+					links = append(links, "http://"+r.Host+"/czds/downloads/"+tld+".zone")
+				}
+			}
+			json.NewEncoder(w).Encode(links)
+			return
+		}
+		tld := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/czds/downloads/"), ".zone")
+		w.Header().Set("Last-Modified", modified[tld].Format(http.TimeFormat))
+		if r.Method == http.MethodGet && broken[tld] {
+			http.Error(w, "unavailable", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(tld + ".\t86400\tin\tns\tns1." + tld + ".\n"))
+	})
+}
+
+func TestDownloadChangedZonesWatermark(t *testing.T) {
+	since := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	modified := map[string]time.Time{
+		"alpha":   since.Add(-time.Hour),
+		"bravo":   since.Add(time.Hour),
+		"charlie": since.Add(2 * time.Hour),
+	}
+	tests := []struct {
+		name   string
+		broken map[string]bool
+		want   time.Time
+	}{
+		{"all downloaded", nil, modified["charlie"]},
+		{"newest failed", map[string]bool{"charlie": true}, modified["bravo"]},
+		{"older failed", map[string]bool{"bravo": true}, modified["bravo"].Add(-time.Second)},
+		{"all changed failed", map[string]bool{"bravo": true, "charlie": true}, since},
+	}
+	for _, test := range tests {
+		client := newTestClient(t, changedZonesHandler(modified, test.broken))
+		result, err := client.DownloadChangedZones(context.Background(), t.TempDir(), since, nil)
+		if len(test.broken) == 0 && err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+		if len(test.broken) > 0 && err == nil {
+			t.Errorf("%s: got no error", test.name)
+		}
+		if result == nil {
+			t.Fatalf("%s: got no result", test.name)
+		}
+		if !result.LastModified.Equal(test.want) {
+			t.Errorf("%s: got LastModified %s, want %s", test.name, result.LastModified, test.want)
+		}
+		if len(result.Results) != 2 {
+			t.Errorf("%s: got %d results, want only the 2 changed zones", test.name, len(result.Results))
+		}
+	}
+}
+
+func TestDownloadChangedZonesOptions(t *testing.T) {
+	since := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	modified := map[string]time.Time{
+		"alpha":   since.Add(time.Hour),
+		"bravo":   since.Add(2 * time.Hour),
+		"charlie": since.Add(3 * time.Hour),
+	}
+	client := newTestClient(t, changedZonesHandler(modified, nil))
+	newestFirst := func(a, b ZoneMeta) bool { return a.LastModified.After(b.LastModified) }
+	result, err := client.DownloadChangedZones(context.Background(), t.TempDir(), since, &DownloadOptions{
+		Less:     newestFirst,
+		Required: []string{"alpha", "delta"},
+	})
+	if !errors.Is(err, ErrRequiredZoneFailed) || !strings.Contains(err.Error(), "delta") {
+		t.Errorf("got error %v, want %v for delta", err, ErrRequiredZoneFailed)
+	}
+	var order []string
+	for _, zone := range result.Results {
+		order = append(order, zone.TLD)
+	}
+	if strings.Join(order, " ") != "charlie bravo alpha" {
+		t.Errorf("downloaded in order %q, want newest first", order)
+	}
+}
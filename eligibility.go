@@ -0,0 +1,170 @@
+package czds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hasPrivateDataError reports if the request details show the account lacks rights to the zone's private data
+func (ri *RequestsInfo) hasPrivateDataError() bool {
+	return ri.PrivateDataError || (ri.FtpDetails != nil && ri.FtpDetails.PrivateDataError)
+}
+
+// RequestsEligibility splits all of the account's requests into those that can currently be downloaded
+// and those that can not. A request is eligible when it is approved, has not expired,
+// and its TLD does not have a private data error. Private data errors are checked like Client.CheckPrivateData
+// from the newest approved request for each TLD, and the check is reused for Client.PrivateDataTTL
+func (c *Client) RequestsEligibility() (eligible, ineligible []Request, err error) {
+	ctx := context.Background()
+	requests, err := c.GetAllRequests(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := c.now()
+	candidates := make([]Request, 0, len(requests))
+	ineligible = make([]Request, 0)
+	for _, request := range requests {
+		if strings.EqualFold(request.Status, RequestApproved) && !request.ExpiredAt(now) {
+			candidates = append(candidates, request)
+		} else {
+			ineligible = append(ineligible, request)
+		}
+	}
+
+	errs, err := c.privateDataErrors(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eligible = make([]Request, 0, len(candidates))
+	for _, request := range candidates {
+		if errs[strings.ToLower(request.TLD)] != nil {
+			ineligible = append(ineligible, request)
+		} else {
+			eligible = append(eligible, request)
+		}
+	}
+	return eligible, ineligible, nil
+}
+
+// DefaultPrivateDataTTL is how long private data checks are reused when Client.PrivateDataTTL is not set
+const DefaultPrivateDataTTL = 10 * time.Minute
+
+// privateDataCache holds the result of privateDataErrors() for Client.PrivateDataTTL
+type privateDataCache struct {
+	mu      sync.Mutex
+	errs    map[string]error
+	fetched time.Time
+	refresh *privateDataRefresh // refresh currently in progress, if any
+}
+
+// privateDataRefresh is a single check of the account's requests shared by all callers waiting on it
+type privateDataRefresh struct {
+	done      chan struct{}
+	errs      map[string]error
+	err       error
+	cancelled bool // the refresh failed because the context of the caller making it was done
+}
+
+// privateDataError returns ErrPrivateDataError if the account's newest approved request for tld has a private data error
+// nil is returned if the account has no approved request for tld, leaving the download to report the problem
+func (c *Client) privateDataError(ctx context.Context, tld string) error {
+	errs, err := c.privateDataErrors(ctx)
+	if err != nil {
+		return err
+	}
+	return errs[strings.ToLower(strings.TrimSuffix(tld, "."))]
+}
+
+// privateDataErrors returns ErrPrivateDataError for each approved TLD whose request has a private data error, keyed by TLD
+// this checks every zone with a single listing of the account's requests, which is reused for Client.PrivateDataTTL.
+// Concurrent callers needing a refresh share a single check. The returned map must not be modified
+func (c *Client) privateDataErrors(ctx context.Context) (map[string]error, error) {
+	ttl := c.PrivateDataTTL
+	if ttl <= 0 {
+		ttl = DefaultPrivateDataTTL
+	}
+	cache := &c.privateData
+	for {
+		cache.mu.Lock()
+		if cache.errs != nil && c.now().Sub(cache.fetched) <= ttl {
+			errs := cache.errs
+			cache.mu.Unlock()
+			return errs, nil
+		}
+		if refresh := cache.refresh; refresh != nil {
+			// another caller is already refreshing, wait for it
+			cache.mu.Unlock()
+			select {
+			case <-refresh.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			// check again if the refresh was only stopped by the other caller's context
+			if refresh.cancelled && ctx.Err() == nil {
+				continue
+			}
+			return refresh.errs, refresh.err
+		}
+		refresh := &privateDataRefresh{done: make(chan struct{})}
+		cache.refresh = refresh
+		cache.mu.Unlock()
+
+		refresh.errs, refresh.err = c.checkPrivateData(ctx)
+		refresh.cancelled = refresh.err != nil && ctx.Err() != nil
+
+		cache.mu.Lock()
+		if refresh.err == nil {
+			cache.errs = refresh.errs
+			cache.fetched = c.now()
+		}
+		cache.refresh = nil
+		cache.mu.Unlock()
+		close(refresh.done)
+		return refresh.errs, refresh.err
+	}
+}
+
+// checkPrivateData fetches the details of the newest approved request for each TLD to check for private data errors
+func (c *Client) checkPrivateData(ctx context.Context) (map[string]error, error) {
+	approved, err := c.GetAllRequests(ctx, &RequestsFilter{
+		Status: RequestApproved,
+		Sort: RequestsSort{
+			Field:     SortByCreated,
+			Direction: SortDesc,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// older approved requests for a TLD have been replaced, only the newest decides if it can be downloaded
+	newest := make([]Request, 0, len(approved))
+	seen := make(map[string]bool, len(approved))
+	for _, request := range approved {
+		tld := strings.ToLower(request.TLD)
+		if !seen[tld] {
+			seen[tld] = true
+			newest = append(newest, request)
+		}
+	}
+	ids := make([]string, 0, len(newest))
+	for _, request := range newest {
+		ids = append(ids, request.RequestID)
+	}
+	infos, err := c.GetRequestInfoBatch(ctx, ids, DefaultInfoParallel)
+	if err != nil {
+		return nil, err
+	}
+	errs := make(map[string]error)
+	for n, info := range infos {
+		tld := strings.ToLower(newest[n].TLD)
+		if info.hasPrivateDataError() {
+			errs[tld] = fmt.Errorf("%w: %s", ErrPrivateDataError, tld)
+		}
+	}
+	return errs, nil
+}
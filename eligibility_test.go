@@ -0,0 +1,127 @@
+package czds
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrivateDataErrorCached(t *testing.T) {
+	var listings, zones atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/czds/requests/all":
+			listings.Add(1)
+			// newest first, the old example request lost its private data rights but was replaced
+			w.Write([]byte(`{"requests":[
+				{"requestId":"new-example","tld":"example","status":"Approved"},
+				{"requestId":"test","tld":"test","status":"Approved"},
+				{"requestId":"old-example","tld":"example","status":"Approved"}
+			],"totalRequests":3}`))
+		case "/czds/requests/new-example":
+			w.Write([]byte(`{"requestId":"new-example","status":"Approved"}`))
+		case "/czds/requests/test":
+			w.Write([]byte(`{"requestId":"test","status":"Approved","privateDataError":true}`))
+		case "/czds/requests/old-example":
+			t.Error("fetched the details of a replaced request")
+			w.Write([]byte(`{"requestId":"old-example","status":"Approved","privateDataError":true}`))
+		default:
+			zones.Add(1)
+			w.Write([]byte("zone.\t86400\tin\tns\tns1.zone.\n"))
+		}
+	}))
+	client.CheckPrivateData = true
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	client.Now = func() time.Time { return now }
+	dir := t.TempDir()
+
+	for i := 0; i < 3; i++ {
+		err := client.DownloadZone(client.ZoneLink("example"), filepath.Join(dir, "example.zone"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = client.DownloadZone(client.ZoneLink("test"), filepath.Join(dir, "test.zone"))
+		if !errors.Is(err, ErrPrivateDataError) {
+			t.Fatalf("got error %v, want %v", err, ErrPrivateDataError)
+		}
+	}
+	if n := listings.Load(); n != 1 {
+		t.Errorf("listed requests %d times, want 1", n)
+	}
+	if n := zones.Load(); n != 3 {
+		t.Errorf("downloaded %d zones, want 3", n)
+	}
+
+	now = now.Add(DefaultPrivateDataTTL + time.Second)
+	err := client.DownloadZone(client.ZoneLink("example"), filepath.Join(dir, "example.zone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := listings.Load(); n != 2 {
+		t.Errorf("listed requests %d times after the TTL, want 2", n)
+	}
+}
+
+func TestRequestsEligibility(t *testing.T) {
+	var details atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/czds/requests/all":
+			var filter RequestsFilter
+			json.NewDecoder(r.Body).Decode(&filter)
+			if filter.Status == RequestApproved {
+				w.Write([]byte(`{"requests":[
+					{"requestId":"new-example","tld":"example","status":"Approved"},
+					{"requestId":"test","tld":"test","status":"Approved"},
+					{"requestId":"old-example","tld":"example","status":"Approved"}
+				],"totalRequests":3}`))
+				return
+			}
+			w.Write([]byte(`{"requests":[
+				{"requestId":"new-example","tld":"example","status":"Approved"},
+				{"requestId":"test","tld":"test","status":"Approved"},
+				{"requestId":"old-example","tld":"example","status":"Approved"},
+				{"requestId":"denied","tld":"denied","status":"Denied"}
+			],"totalRequests":4}`))
+		case "/czds/requests/new-example":
+			details.Add(1)
+			w.Write([]byte(`{"requestId":"new-example","status":"Approved"}`))
+		case "/czds/requests/test":
+			details.Add(1)
+			w.Write([]byte(`{"requestId":"test","status":"Approved","privateDataError":true}`))
+		default:
+			t.Errorf("unexpected request for %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+
+	for i := 0; i < 2; i++ {
+		eligible, ineligible, err := client.RequestsEligibility()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := requestIDs(eligible); got != "new-example old-example" {
+			t.Errorf("got eligible %s", got)
+		}
+		if got := requestIDs(ineligible); got != "denied test" {
+			t.Errorf("got ineligible %s", got)
+		}
+	}
+	if n := details.Load(); n != 2 {
+		t.Errorf("fetched request details %d times, want 2", n)
+	}
+}
+
+// requestIDs returns the IDs of requests joined by spaces
+func requestIDs(requests []Request) string {
+	ids := make([]string, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.RequestID)
+	}
+	return strings.Join(ids, " ")
+}
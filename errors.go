@@ -0,0 +1,112 @@
+package czds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is returned when the server rejects the client's credentials or token
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the account is not allowed to access the requested resource
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned when the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrSFTPOnly is returned when a zone is only delivered over SFTP and can not be downloaded over HTTPS
+	ErrSFTPOnly = errors.New("zone is only available over SFTP")
+	// ErrNoApprovedTLDs is returned when the account is not approved to download any zones
+	ErrNoApprovedTLDs = errors.New("no approved TLDs")
+	// ErrTooManyFailures is returned when a batch of downloads is aborted after reaching DownloadOptions.AbortAfterFailures
+	ErrTooManyFailures = errors.New("too many failures")
+	// ErrRequiredZoneFailed is returned when a zone listed in DownloadOptions.Required fails to download
+	ErrRequiredZoneFailed = errors.New("required zone failed")
+	// ErrDeadlineBudget is returned for zones that could not be downloaded before DownloadOptions.Deadline
+	ErrDeadlineBudget = errors.New("download deadline budget exhausted")
+	// ErrTermsChanged is returned when a request is submitted with a terms and conditions version
+	// that is no longer current
+	ErrTermsChanged = errors.New("terms and conditions version changed")
+	// ErrPrivateDataError is returned when downloading a zone that the account's request shows it lacks
+	// rights to the private data of, see Client.CheckPrivateData
+	ErrPrivateDataError = errors.New("account lacks rights to the zone's private data")
+	// ErrTermsUnavailable is returned instead of submitting a request when the server returns
+	// terms and conditions without a usable version, this is usually temporary so may be retried
+	ErrTermsUnavailable = errors.New("terms and conditions unavailable")
+	// ErrReadOnly is returned instead of making a request that would change the account when Client.ReadOnly is set
+	ErrReadOnly = errors.New("client is read only")
+	// ErrResponseTooLarge is returned when a JSON API response is larger than the client's limit, see WithMaxResponseBytes()
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrEmptyReport is returned when the requests report is empty, after any retries from WithEmptyReportRetry()
+	ErrEmptyReport = errors.New("requests report was empty")
+)
+
+// maxErrorBody is the most of a failed response's body read for StatusError.Message
+const maxErrorBody = 4096
+
+// StatusError is returned when an API request gets a response with an unexpected HTTP status
+// use errors.Is() to check for the typed errors it maps to, such as ErrUnauthorized
+type StatusError struct {
+	URL        string
+	Status     string
+	StatusCode int
+	Message    string // error message returned by the server, if any
+}
+
+// newStatusError creates a StatusError for resp, reading the server's error message from the body
+func newStatusError(url string, resp *http.Response) *StatusError {
+	e := &StatusError{
+		URL:        url,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil || len(body) == 0 {
+		return e
+	}
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &msg) == nil && msg.Message != "" {
+		e.Message = msg.Message
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("Error on request %s, got Status %s %s: %s", e.URL, e.Status, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("Error on request %s, got Status %s %s", e.URL, e.Status, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns the typed error for the response's status code, if any
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isTermsError reports if err is the server rejecting a submission for using stale terms and conditions
+func isTermsError(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.StatusCode {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusPreconditionFailed:
+		msg := strings.ToLower(statusErr.Message)
+		return strings.Contains(msg, "terms") || strings.Contains(msg, "tcversion")
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package czds
+
+import (
+	"math"
+	"time"
+)
+
+// HasExpiration reports whether the request has an expiration time set
+func (r *Request) HasExpiration() bool {
+	return r.Expired.Unix() > 0
+}
+
+// DaysUntilExpiration returns the number of whole days until the request expires, rounded down
+// negative values mean the request has already expired.
+// The bool is false if the request has no expiration set
+func (r *Request) DaysUntilExpiration() (int, bool) {
+	return r.DaysUntilExpirationAt(time.Now())
+}
+
+// DaysUntilExpirationAt is DaysUntilExpiration() measured from now instead of the current time
+func (r *Request) DaysUntilExpirationAt(now time.Time) (int, bool) {
+	if !r.HasExpiration() {
+		return 0, false
+	}
+	days := math.Floor(r.Expired.Sub(now).Hours() / 24)
+	return int(days), true
+}
+
+// ExpiringSoon reports whether the request has an expiration set that is within the provided duration from now
+// requests that have already expired are also considered expiring soon
+func (r *Request) ExpiringSoon(within time.Duration) bool {
+	return r.ExpiringSoonAt(within, time.Now())
+}
+
+// ExpiringSoonAt is ExpiringSoon() measured from now instead of the current time
+func (r *Request) ExpiringSoonAt(within time.Duration, now time.Time) bool {
+	if !r.HasExpiration() {
+		return false
+	}
+	return r.Expired.Sub(now) <= within
+}
+
+// ExpiredAt reports whether the request has an expiration set that is before now
+func (r *Request) ExpiredAt(now time.Time) bool {
+	return r.HasExpiration() && r.Expired.Before(now)
+}
@@ -0,0 +1,229 @@
+package czds
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats for the export and report helpers
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"  // a single JSON array
+	FormatJSONL = "jsonl" // newline delimited JSON objects
+)
+
+// FormatRequestsTable writes reqs to w as an aligned text table with the columns TLD, Status, Created, Expires, and SFTP
+// requests without an expiration are shown as expiring "never"
+func FormatRequestsTable(w io.Writer, reqs []Request) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, err := fmt.Fprintln(tw, "TLD\tStatus\tCreated\tExpires\tSFTP")
+	if err != nil {
+		return err
+	}
+	for _, request := range reqs {
+		expires := "never"
+		if request.HasExpiration() {
+			expires = request.Expired.Format(time.ANSIC)
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n",
+			request.TLD,
+			request.Status,
+			request.Created.Format(time.ANSIC),
+			expires,
+			request.SFTP)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// CSVLabels selects which TLD label columns WriteRequestsCSV() writes
+type CSVLabels int
+
+// Options for CSVOptions.Labels
+const (
+	CSVLabelsBoth   CSVLabels = iota // both the A-label and U-label columns
+	CSVLabelsALabel                  // only the A-label (punycode) column
+	CSVLabelsULabel                  // only the U-label (unicode) column
+)
+
+// Default column headers for the TLD labels written by WriteRequestsCSV()
+const (
+	DefaultCSVALabelHeader = "tld"
+	DefaultCSVULabelHeader = "uLabel"
+)
+
+// CSVOptions configures the output of WriteRequestsCSV()
+type CSVOptions struct {
+	Labels       CSVLabels // which label columns to write, defaults to both
+	ALabelHeader string    // header for the A-label column, defaults to DefaultCSVALabelHeader
+	ULabelHeader string    // header for the U-label column, defaults to DefaultCSVULabelHeader
+}
+
+// WriteRequestsCSV writes reqs to w as CSV with a header row
+// the U-label column is named with the correct spelling rather than the API's "ulable" unless changed in opts.
+// Times are written as RFC3339 and requests without an expiration have an empty expired column
+func WriteRequestsCSV(w io.Writer, reqs []Request, opts *CSVOptions) error {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	aLabelHeader := opts.ALabelHeader
+	if aLabelHeader == "" {
+		aLabelHeader = DefaultCSVALabelHeader
+	}
+	uLabelHeader := opts.ULabelHeader
+	if uLabelHeader == "" {
+		uLabelHeader = DefaultCSVULabelHeader
+	}
+	writeALabel := opts.Labels != CSVLabelsULabel
+	writeULabel := opts.Labels != CSVLabelsALabel
+
+	header := []string{"requestId"}
+	if writeALabel {
+		header = append(header, aLabelHeader)
+	}
+	if writeULabel {
+		header = append(header, uLabelHeader)
+	}
+	header = append(header, "status", "created", "lastUpdated", "expired", "sftp")
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, request := range reqs {
+		row := []string{request.RequestID}
+		if writeALabel {
+			row = append(row, request.TLD)
+		}
+		if writeULabel {
+			row = append(row, request.ULabel)
+		}
+		var expired string
+		if request.HasExpiration() {
+			expired = request.Expired.Format(time.RFC3339)
+		}
+		row = append(row,
+			request.Status,
+			request.Created.Format(time.RFC3339),
+			request.LastUpdated.Format(time.RFC3339),
+			expired,
+			strconv.FormatBool(request.SFTP))
+		err = cw.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tldStatusRecord is the serialized form of a TLDStatus written by WriteTLDStatus()
+// the U-label field is named with the correct spelling rather than the API's "ulable"
+type tldStatusRecord struct {
+	TLD    string `json:"tld"`
+	ULabel string `json:"uLabel"`
+	Status string `json:"status"`
+	SFTP   bool   `json:"sftp"`
+}
+
+// WriteTLDStatus writes the catalog tlds from GetTLDStatus() to w in format, either FormatCSV or FormatJSON
+// both formats use the fields tld, uLabel, status, and sftp so snapshots can be compared over time with DiffTLDStatus()
+func WriteTLDStatus(w io.Writer, format string, tlds []TLDStatus) error {
+	switch format {
+	case FormatJSON:
+		records := make([]tldStatusRecord, 0, len(tlds))
+		for _, tld := range tlds {
+			records = append(records, tldStatusRecord{
+				TLD:    tld.TLD,
+				ULabel: tld.ULabel,
+				Status: tld.CurrentStatus,
+				SFTP:   tld.SFTP,
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		err := cw.Write([]string{"tld", "uLabel", "status", "sftp"})
+		if err != nil {
+			return err
+		}
+		for _, tld := range tlds {
+			err = cw.Write([]string{tld.TLD, tld.ULabel, tld.CurrentStatus, strconv.FormatBool(tld.SFTP)})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unsupported TLD status format %q", format)
+}
+
+// ReadTLDStatus reads a catalog written by WriteTLDStatus() in format, either FormatCSV or FormatJSON
+// CSV columns are matched by their header so they may be in any order
+func ReadTLDStatus(r io.Reader, format string) ([]TLDStatus, error) {
+	switch format {
+	case FormatJSON:
+		var records []tldStatusRecord
+		err := json.NewDecoder(r).Decode(&records)
+		if err != nil {
+			return nil, err
+		}
+		tlds := make([]TLDStatus, 0, len(records))
+		for _, record := range records {
+			tlds = append(tlds, TLDStatus{
+				TLD:           record.TLD,
+				ULabel:        record.ULabel,
+				CurrentStatus: record.Status,
+				SFTP:          record.SFTP,
+			})
+		}
+		return tlds, nil
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		header, err := cr.Read()
+		if err != nil {
+			return nil, err
+		}
+		columns := make(map[string]int, len(header))
+		for n, name := range header {
+			columns[name] = n
+		}
+		for _, name := range []string{"tld", "uLabel", "status", "sftp"} {
+			if _, ok := columns[name]; !ok {
+				return nil, fmt.Errorf("TLD status CSV missing %q column", name)
+			}
+		}
+		tlds := make([]TLDStatus, 0)
+		for {
+			row, err := cr.Read()
+			if err == io.EOF {
+				return tlds, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			sftp, err := strconv.ParseBool(row[columns["sftp"]])
+			if err != nil {
+				return nil, fmt.Errorf("TLD status CSV line %d: %w", len(tlds)+2, err)
+			}
+			tlds = append(tlds, TLDStatus{
+				TLD:           row[columns["tld"]],
+				ULabel:        row[columns["uLabel"]],
+				CurrentStatus: row[columns["status"]],
+				SFTP:          sftp,
+			})
+		}
+	}
+	return nil, fmt.Errorf("unsupported TLD status format %q", format)
+}
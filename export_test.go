@@ -0,0 +1,41 @@
+package czds
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestFormatRequestsTable(t *testing.T) {
+	created := time.Date(2019, time.May, 1, 10, 30, 0, 0, time.UTC)
+	reqs := []Request{
+		{TLD: "example", Status: RequestApproved, Created: created, Expired: created.AddDate(1, 0, 0), SFTP: true},
+		{TLD: "xn--p1ai", Status: RequestPending, Created: created},
+		{TLD: "test", Status: RequestApproved, Created: created, Expired: time.Unix(0, 0)},
+	}
+	var buf bytes.Buffer
+	err := FormatRequestsTable(&buf, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "requests_table.golden")
+	if *update {
+		err = os.WriteFile(golden, buf.Bytes(), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got table:\n%s\nwant:\n%s", buf.Bytes(), want)
+	}
+}
@@ -1 +1,3 @@
 module github.com/lanrat/czds
+
+go 1.20
@@ -0,0 +1,162 @@
+package czds
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// flexTime decodes the timestamp formats seen from the CZDS API:
+// RFC3339 strings, unix timestamps (in seconds or milliseconds) and null.
+// null, 0, and the unix epoch are all decoded as the zero time.Time to signify an unset time
+type flexTime time.Time
+
+// time layouts accepted for string timestamps, in the order tried
+var flexTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// unix timestamps larger than this are assumed to be in milliseconds
+const maxUnixSeconds = 1e11
+
+func (t *flexTime) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*t = flexTime{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			*t = flexTime{}
+			return nil
+		}
+		for _, layout := range flexTimeLayouts {
+			parsed, err := time.Parse(layout, s)
+			if err == nil {
+				*t = flexTime(unsetEpoch(parsed))
+				return nil
+			}
+		}
+		// some timestamps are sent as quoted numbers
+		data = []byte(s)
+	}
+
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to decode %s as a time", data)
+	}
+	if n > maxUnixSeconds || n < -maxUnixSeconds {
+		*t = flexTime(unsetEpoch(time.UnixMilli(n).UTC()))
+		return nil
+	}
+	*t = flexTime(unsetEpoch(time.Unix(n, 0).UTC()))
+	return nil
+}
+
+// unsetEpoch returns the zero time for the unix epoch and t otherwise
+func unsetEpoch(t time.Time) time.Time {
+	if t.Unix() == 0 {
+		return time.Time{}
+	}
+	return t
+}
+
+// UnmarshalJSON decodes a Request accepting any of the time formats sent by the API
+// the U-label is read from either the API's misspelled "ulable" key or "ulabel"
+func (r *Request) UnmarshalJSON(data []byte) error {
+	type request Request
+	aux := struct {
+		*request
+		Created     flexTime `json:"created"`
+		LastUpdated flexTime `json:"last_updated"`
+		Expired     flexTime `json:"expired"`
+		ULabel      string   `json:"ulabel"`
+	}{request: (*request)(r)}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	r.Created = time.Time(aux.Created)
+	r.LastUpdated = time.Time(aux.LastUpdated)
+	r.Expired = time.Time(aux.Expired)
+	if r.ULabel == "" {
+		r.ULabel = aux.ULabel
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a TLDStatus reading the U-label from either the API's misspelled "ulable" key or "ulabel"
+// this also applies to the TLD of a RequestsInfo
+func (ts *TLDStatus) UnmarshalJSON(data []byte) error {
+	type tldStatus TLDStatus
+	aux := struct {
+		*tldStatus
+		ULabel string `json:"ulabel"`
+	}{tldStatus: (*tldStatus)(ts)}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	if ts.ULabel == "" {
+		ts.ULabel = aux.ULabel
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a RequestsInfo accepting any of the time formats sent by the API
+func (ri *RequestsInfo) UnmarshalJSON(data []byte) error {
+	type requestsInfo RequestsInfo
+	aux := struct {
+		*requestsInfo
+		Created     flexTime `json:"created"`
+		LastUpdated flexTime `json:"last_updated"`
+		Expired     flexTime `json:"expired"`
+	}{requestsInfo: (*requestsInfo)(ri)}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	ri.Created = time.Time(aux.Created)
+	ri.LastUpdated = time.Time(aux.LastUpdated)
+	ri.Expired = time.Time(aux.Expired)
+	return nil
+}
+
+// UnmarshalJSON decodes a HistoryEntry accepting any of the time formats sent by the API
+func (he *HistoryEntry) UnmarshalJSON(data []byte) error {
+	type historyEntry HistoryEntry
+	aux := struct {
+		*historyEntry
+		Timestamp flexTime `json:"timestamp"`
+	}{historyEntry: (*historyEntry)(he)}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	he.Timestamp = time.Time(aux.Timestamp)
+	return nil
+}
+
+// UnmarshalJSON decodes Terms accepting any of the time formats sent by the API
+func (t *Terms) UnmarshalJSON(data []byte) error {
+	type terms Terms
+	aux := struct {
+		*terms
+		Created flexTime `json:"created"`
+	}{terms: (*terms)(t)}
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+	t.Created = time.Time(aux.Created)
+	return nil
+}
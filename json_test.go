@@ -0,0 +1,161 @@
+package czds
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFlexTimeUnmarshalJSON(t *testing.T) {
+	want := time.Date(2019, time.May, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{"RFC3339", `"2019-05-01T10:30:00Z"`, want},
+		{"RFC3339 offset", `"2019-05-01T12:30:00+02:00"`, want},
+		{"RFC3339 fraction", `"2019-05-01T10:30:00.000Z"`, want},
+		{"Z0700", `"2019-05-01T10:30:00.000+0000"`, want},
+		{"Z0700 offset", `"2019-05-01T12:30:00.000+0200"`, want},
+		{"unix seconds", `1556706600`, want},
+		{"unix milliseconds", `1556706600000`, want},
+		{"quoted seconds", `"1556706600"`, want},
+		{"quoted milliseconds", `"1556706600000"`, want},
+		{"null", `null`, time.Time{}},
+		{"zero", `0`, time.Time{}},
+		{"quoted zero", `"0"`, time.Time{}},
+		{"empty string", `""`, time.Time{}},
+		{"epoch", `"1970-01-01T00:00:00Z"`, time.Time{}},
+	}
+	for _, test := range tests {
+		var got flexTime
+		err := json.Unmarshal([]byte(test.json), &got)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if !time.Time(got).Equal(test.want) {
+			t.Errorf("%s: decoded %s as %s, want %s", test.name, test.json, time.Time(got), test.want)
+		}
+	}
+}
+
+func TestFlexTimeUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{`"yesterday"`, `true`, `"2019-05-01"`, `1.5`} {
+		var got flexTime
+		err := json.Unmarshal([]byte(data), &got)
+		if err == nil {
+			t.Errorf("decoded %s as %s, want an error", data, time.Time(got))
+		}
+	}
+}
+
+func TestRequestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		ulabel      string
+		created     time.Time
+		lastUpdated time.Time
+	}{
+		{
+			name:        "ulable",
+			json:        `{"tld":"xn--p1ai","ulable":"рф","created":"2019-05-01T10:30:00Z","last_updated":1556706600000}`,
+			ulabel:      "рф",
+			created:     time.Date(2019, time.May, 1, 10, 30, 0, 0, time.UTC),
+			lastUpdated: time.Date(2019, time.May, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "ulabel",
+			json:   `{"tld":"xn--p1ai","ulabel":"рф","created":null}`,
+			ulabel: "рф",
+		},
+		{
+			name:   "both prefer ulable",
+			json:   `{"tld":"xn--p1ai","ulable":"рф","ulabel":"other"}`,
+			ulabel: "рф",
+		},
+		{
+			name: "neither",
+			json: `{"tld":"com","created":0}`,
+		},
+	}
+	for _, test := range tests {
+		var request Request
+		err := json.Unmarshal([]byte(test.json), &request)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if request.ULabel != test.ulabel {
+			t.Errorf("%s: got ULabel %q, want %q", test.name, request.ULabel, test.ulabel)
+		}
+		if !request.Created.Equal(test.created) {
+			t.Errorf("%s: got Created %s, want %s", test.name, request.Created, test.created)
+		}
+		if !request.LastUpdated.Equal(test.lastUpdated) {
+			t.Errorf("%s: got LastUpdated %s, want %s", test.name, request.LastUpdated, test.lastUpdated)
+		}
+	}
+}
+
+func TestTLDStatusUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		ulabel string
+	}{
+		{"ulable", `{"tld":"xn--p1ai","ulable":"рф","currentStatus":"approved","sftp":true}`, "рф"},
+		{"ulabel", `{"tld":"xn--p1ai","ulabel":"рф","currentStatus":"approved","sftp":true}`, "рф"},
+		{"both prefer ulable", `{"tld":"xn--p1ai","ulable":"рф","ulabel":"other","currentStatus":"approved","sftp":true}`, "рф"},
+		{"neither", `{"tld":"xn--p1ai","currentStatus":"approved","sftp":true}`, ""},
+	}
+	for _, test := range tests {
+		var status TLDStatus
+		err := json.Unmarshal([]byte(test.json), &status)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if status.ULabel != test.ulabel {
+			t.Errorf("%s: got ULabel %q, want %q", test.name, status.ULabel, test.ulabel)
+		}
+		if status.TLD != "xn--p1ai" || status.CurrentStatus != "approved" || !status.SFTP {
+			t.Errorf("%s: fields not decoded: %+v", test.name, status)
+		}
+	}
+}
+
+func TestRequestsInfoUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		ulabel string
+	}{
+		{"ulable", `{"requestId":"1","tld":{"tld":"xn--p1ai","ulable":"рф"},"created":"2019-05-01T10:30:00.000+0000","expired":null}`, "рф"},
+		{"ulabel", `{"requestId":"1","tld":{"tld":"xn--p1ai","ulabel":"рф"},"created":1556706600,"expired":0}`, "рф"},
+	}
+	created := time.Date(2019, time.May, 1, 10, 30, 0, 0, time.UTC)
+	for _, test := range tests {
+		var info RequestsInfo
+		err := json.Unmarshal([]byte(test.json), &info)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if info.TLD == nil {
+			t.Errorf("%s: TLD not decoded", test.name)
+			continue
+		}
+		if info.TLD.ULabel != test.ulabel {
+			t.Errorf("%s: got ULabel %q, want %q", test.name, info.TLD.ULabel, test.ulabel)
+		}
+		if !info.Created.Equal(created) {
+			t.Errorf("%s: got Created %s, want %s", test.name, info.Created, created)
+		}
+		if !info.Expired.IsZero() {
+			t.Errorf("%s: got Expired %s, want the zero time", test.name, info.Expired)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package czds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Labels are key/value pairs associated with a request locally, such as the project or owner it belongs to
+// the API has no concept of labels, they only exist in a LabelStore
+type Labels map[string]string
+
+// LabelStore persists the local Labels of requests by RequestID
+// implementations must be safe for concurrent use
+type LabelStore interface {
+	// Labels returns the labels of requestID, or nil if it has none
+	Labels(requestID string) (Labels, error)
+	// SetLabels replaces the labels of requestID, empty labels remove them
+	SetLabels(requestID string, labels Labels) error
+}
+
+// DefaultLabelKeyPrefix is prepended to RequestIDs to form the keys used by NewCacheLabelStore()
+const DefaultLabelKeyPrefix = "labels:"
+
+// CacheLabelStore is a LabelStore that saves each request's labels as JSON in a Cache
+type CacheLabelStore struct {
+	Cache  Cache
+	Prefix string // prepended to RequestIDs to form the Cache keys
+}
+
+// NewCacheLabelStore returns a CacheLabelStore saving labels in cache using DefaultLabelKeyPrefix
+func NewCacheLabelStore(cache Cache) *CacheLabelStore {
+	return &CacheLabelStore{
+		Cache:  cache,
+		Prefix: DefaultLabelKeyPrefix,
+	}
+}
+
+// Labels implements LabelStore
+func (s *CacheLabelStore) Labels(requestID string) (Labels, error) {
+	value, ok, err := s.Cache.Get(s.Prefix + requestID)
+	if err != nil || !ok || len(value) == 0 {
+		return nil, err
+	}
+	var labels Labels
+	err = json.Unmarshal(value, &labels)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode labels of request %s: %w", requestID, err)
+	}
+	return labels, nil
+}
+
+// SetLabels implements LabelStore
+func (s *CacheLabelStore) SetLabels(requestID string, labels Labels) error {
+	if len(labels) == 0 {
+		return s.Cache.Set(s.Prefix+requestID, nil)
+	}
+	value, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	return s.Cache.Set(s.Prefix+requestID, value)
+}
+
+// SetRequestLabel sets the label key to value on requestID in store, keeping its other labels
+// the labels are read and written back, so concurrent changes to the same request may be lost
+func SetRequestLabel(store LabelStore, requestID, key, value string) error {
+	labels, err := store.Labels(requestID)
+	if err != nil {
+		return err
+	}
+	if labels == nil {
+		labels = make(Labels)
+	}
+	labels[key] = value
+	return store.SetLabels(requestID, labels)
+}
+
+// RemoveRequestLabel removes the label key from requestID in store, keeping its other labels
+func RemoveRequestLabel(store LabelStore, requestID, key string) error {
+	labels, err := store.Labels(requestID)
+	if err != nil {
+		return err
+	}
+	if _, ok := labels[key]; !ok {
+		return nil
+	}
+	delete(labels, key)
+	return store.SetLabels(requestID, labels)
+}
+
+// FilterByLabel returns the requests from reqs whose label key in store is value, in the same order
+// an empty value matches every request that has the label key with any value
+func FilterByLabel(store LabelStore, reqs []Request, key, value string) ([]Request, error) {
+	matches := make([]Request, 0)
+	for _, request := range reqs {
+		labels, err := store.Labels(request.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		if labelMatches(labels, key, value) {
+			matches = append(matches, request)
+		}
+	}
+	return matches, nil
+}
+
+// labelMatches reports if labels has key set to value, or set at all if value is empty
+func labelMatches(labels Labels, key, value string) bool {
+	got, ok := labels[key]
+	return ok && (value == "" || got == value)
+}
+
+// GetRequestsByLabel returns all requests matching filter whose label key in store is value, see FilterByLabel()
+// a nil filter searches all requests, newest first
+func (c *Client) GetRequestsByLabel(ctx context.Context, store LabelStore, filter *RequestsFilter, key, value string) ([]Request, error) {
+	matches := make([]Request, 0)
+	err := c.ForEachRequest(ctx, filter, func(request Request) error {
+		labels, err := store.Labels(request.RequestID)
+		if err != nil {
+			return err
+		}
+		if labelMatches(labels, key, value) {
+			matches = append(matches, request)
+		}
+		return nil
+	})
+	return matches, err
+}
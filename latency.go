@@ -0,0 +1,117 @@
+package czds
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ApprovalStats summarizes how long requests took to be approved, from ApprovalLatencyStats()
+// the durations are zero if Count is 0
+type ApprovalStats struct {
+	Count  int // number of approved requests measured
+	Min    time.Duration
+	Median time.Duration
+	P95    time.Duration // 95th percentile
+	Max    time.Duration
+}
+
+// ApprovalLatencyStats measures the time from creation to approval of every request that was approved,
+// including requests that have since expired or been revoked. Pending, submitted, and denied requests are excluded.
+// The approval time is the first history entry recording the change to approved
+func (c *Client) ApprovalLatencyStats(ctx context.Context) (ApprovalStats, error) {
+	requests, err := c.GetRequestsByStatuses(ctx, []string{RequestApproved, RequestExpired, RequestRevoked}, nil)
+	if err != nil {
+		return ApprovalStats{}, err
+	}
+	ids := make([]string, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.RequestID)
+	}
+	infos, err := c.GetRequestInfoBatch(ctx, ids, DefaultInfoParallel)
+	if err != nil {
+		return ApprovalStats{}, err
+	}
+
+	latencies := make([]time.Duration, 0, len(infos))
+	for _, info := range infos {
+		approved, ok := approvalTime(info.History)
+		if !ok || info.Created.IsZero() || approved.Before(info.Created) {
+			continue
+		}
+		latencies = append(latencies, approved.Sub(info.Created))
+	}
+	return approvalStats(latencies), nil
+}
+
+// approvalTime returns the time of the earliest history entry recording an approval
+func approvalTime(history []HistoryEntry) (time.Time, bool) {
+	var approved time.Time
+	for _, event := range history {
+		if !strings.Contains(strings.ToLower(event.Action), StatusApproved) {
+			continue
+		}
+		if approved.IsZero() || event.Timestamp.Before(approved) {
+			approved = event.Timestamp
+		}
+	}
+	return approved, !approved.IsZero()
+}
+
+// approvalStats computes the summary of latencies using nearest rank percentiles
+func approvalStats(latencies []time.Duration) ApprovalStats {
+	stats := ApprovalStats{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p * float64(len(latencies))))
+		if rank < 1 {
+			rank = 1
+		}
+		return latencies[rank-1]
+	}
+	stats.Min = latencies[0]
+	stats.Median = percentile(0.5)
+	stats.P95 = percentile(0.95)
+	stats.Max = latencies[len(latencies)-1]
+	return stats
+}
+
+// StalePendingRequests returns the pending requests that have not changed status for longer than olderThan,
+// oldest first, so requests that may be stuck waiting on ICANN can be followed up. A request's age is measured
+// from its last update, which is its last status change, or from when it was created if it has never been updated
+func (c *Client) StalePendingRequests(olderThan time.Duration) ([]Request, error) {
+	pending, err := c.GetAllRequests(context.Background(), &RequestsFilter{
+		Status: RequestPending,
+		Sort: RequestsSort{
+			Field:     SortByLastUpdated,
+			Direction: SortAsc,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cutoff := c.now().Add(-olderThan)
+	stale := make([]Request, 0)
+	for _, request := range pending {
+		if pendingSince(&request).Before(cutoff) {
+			stale = append(stale, request)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return pendingSince(&stale[i]).Before(pendingSince(&stale[j]))
+	})
+	return stale, nil
+}
+
+// pendingSince returns when the request last changed status
+func pendingSince(r *Request) time.Time {
+	if r.LastUpdated.After(r.Created) {
+		return r.LastUpdated
+	}
+	return r.Created
+}
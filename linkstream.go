@@ -0,0 +1,150 @@
+package czds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DownloadLink is a zone download link streamed by GetZoneDownloadLinksStream()
+type DownloadLink struct {
+	TLD string
+	URL string
+}
+
+// GetZoneDownloadLinksStream calls fn with each of the download links available to the authenticated user
+// as they are decoded from the response, rather than buffering the whole list like GetLinks().
+// Streaming stops at the first error returned by fn, which is returned.
+// The response does not hold a slot under WithMaxConcurrency() while it is streamed, so fn may make requests
+func (c *Client) GetZoneDownloadLinksStream(ctx context.Context, fn func(DownloadLink) error) error {
+	resp, err := c.apiRequest(ctx, true, "GET", c.BaseURL+"/czds/downloads/links", nil, withStreamedBody())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(c.limitResponse(resp.Body))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("download links response is not a JSON array")
+	}
+	for decoder.More() {
+		var link string
+		err = decoder.Decode(&link)
+		if err != nil {
+			return err
+		}
+		err = fn(DownloadLink{
+			TLD: TLDFromLink(link),
+			URL: link,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// DownloadZonesStream downloads every zone available to the authenticated user into dir like DownloadZones(),
+// but starts downloading zones as their links are streamed with GetZoneDownloadLinksStream() instead of waiting
+// for the whole list. Results are returned in the order the links were received.
+// A schedule or Deadline in opts needs every link before starting, so when one is set this is the same as DownloadZones()
+func (c *Client) DownloadZonesStream(ctx context.Context, dir string, opts *DownloadOptions) ([]ZoneResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	if opts.Less != nil || opts.SortBySize || !opts.Deadline.IsZero() {
+		return c.DownloadZones(ctx, dir, opts)
+	}
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = DefaultDownloadParallel
+	}
+	var privateDataErrs map[string]error
+	if c.CheckPrivateData {
+		var err error
+		privateDataErrs, err = c.privateDataErrors(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// cancelled to stop in-flight downloads under FailFast
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	failures := newFailureTracker(opts, cancel)
+
+	// links and results grow as links are streamed and are guarded by mu
+	var mu sync.Mutex
+	links := make([]string, 0)
+	results := make([]ZoneResult, 0)
+
+	type indexedJob struct {
+		n   int
+		job zoneJob
+	}
+	work := make(chan indexedJob)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next := range work {
+				result := c.downloadLink(ctx, dir, next.job, opts)
+				mu.Lock()
+				results[next.n] = result
+				mu.Unlock()
+				failures.record(ctx, result.Err)
+			}
+		}()
+	}
+
+	streamErr := c.GetZoneDownloadLinksStream(ctx, func(link DownloadLink) error {
+		mu.Lock()
+		n := len(links)
+		links = append(links, link.URL)
+		results = append(results, ZoneResult{TLD: link.TLD, URL: link.URL})
+		mu.Unlock()
+		job := zoneJob{
+			link: link.URL,
+			err:  privateDataErrs[strings.ToLower(link.TLD)],
+		}
+		select {
+		case work <- indexedJob{n: n, job: job}:
+			return nil
+		case <-ctx.Done():
+			mu.Lock()
+			results[n].Err = ctx.Err()
+			mu.Unlock()
+			return ctx.Err()
+		}
+	})
+	close(work)
+	wg.Wait()
+
+	if err := parentCtx.Err(); err != nil {
+		return results, err
+	}
+	if err := failures.err(); err != nil {
+		return results, err
+	}
+	if streamErr != nil {
+		return results, streamErr
+	}
+	if len(links) == 0 {
+		return nil, ErrNoApprovedTLDs
+	}
+	err := resultsError(results, opts)
+	if missingErr := missingRequired(links, opts); missingErr != nil {
+		err = errors.Join(missingErr, err)
+	}
+	return results, err
+}
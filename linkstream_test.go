@@ -0,0 +1,54 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadZonesStreamMaxConcurrency(t *testing.T) {
+	tlds := []string{"example", "test", "invalid"}
+	var heads atomic.Int32
+	client := newTestClient(t, zoneHandler(tlds, &heads), WithMaxConcurrency(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results, err := client.DownloadZonesStream(ctx, t.TempDir(), &DownloadOptions{Parallel: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(tlds) {
+		t.Fatalf("got %d results, want %d", len(results), len(tlds))
+	}
+	for n, result := range results {
+		if result.TLD != tlds[n] || result.Err != nil {
+			t.Errorf("result %d: got %s with error %v", n, result.TLD, result.Err)
+		}
+	}
+}
+
+func TestDownloadZonesStreamAbortAfterFailures(t *testing.T) {
+	tlds := []string{"example", "test", "invalid", "localhost"}
+	var heads atomic.Int32
+	zones := zoneHandler(tlds, &heads)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/czds/downloads/links" {
+			http.Error(w, "unavailable", http.StatusForbidden)
+			return
+		}
+		zones.ServeHTTP(w, r)
+	}))
+
+	results, err := client.DownloadZonesStream(context.Background(), t.TempDir(), &DownloadOptions{Parallel: 1, AbortAfterFailures: 2})
+	if !errors.Is(err, ErrTooManyFailures) {
+		t.Fatalf("got error %v, want %v", err, ErrTooManyFailures)
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("%s has no error", result.TLD)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package czds
+
+import (
+	"context"
+)
+
+// Logger receives log messages from a Client, *log.Logger satisfies this interface
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID id
+// log messages for requests made with the returned context are prefixed with the ID so a single
+// logical operation can be traced across its retries, re-authentication and other sub-requests
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" if there is none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// logf logs to the client's Logger, if set, prefixed with the correlation ID of ctx
+func (c *Client) logf(ctx context.Context, format string, v ...interface{}) {
+	if c.Logger == nil {
+		return
+	}
+	if id := CorrelationID(ctx); id != "" {
+		format = "[" + id + "] " + format
+	}
+	c.Logger.Printf(format, v...)
+}
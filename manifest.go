@@ -0,0 +1,57 @@
+package czds
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// ManifestEntry describes a single zone in a manifest written by WriteManifest()
+type ManifestEntry struct {
+	TLD          string     `json:"tld"`
+	Filename     string     `json:"filename,omitempty"`
+	Size         int64      `json:"size"`
+	SHA256       string     `json:"sha256,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"` // Last-Modified time reported by the server
+	DownloadedAt *time.Time `json:"downloadedAt,omitempty"`
+	NotModified  bool       `json:"notModified,omitempty"`
+	Error        string     `json:"error,omitempty"` // set for zones that failed to download
+}
+
+// WriteManifest writes a JSON manifest of the zones in results from DownloadZones() to w
+// listing each zone's filename, size, SHA-256 hash, Last-Modified time, and when it was downloaded.
+// Failed zones are included with their error so incomplete runs can be detected.
+// Zones that were not modified do not have a hash as they were not downloaded
+func WriteManifest(w io.Writer, results []ZoneResult) error {
+	entries := make([]ManifestEntry, 0, len(results))
+	for n := range results {
+		result := &results[n]
+		entry := ManifestEntry{
+			TLD:          result.TLD,
+			Size:         result.Size,
+			SHA256:       result.SHA256,
+			LastModified: optionalTime(result.LastModified),
+			DownloadedAt: optionalTime(result.DownloadedAt),
+			NotModified:  result.NotModified,
+		}
+		if result.Path != "" {
+			entry.Filename = filepath.Base(result.Path)
+		}
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+		entries = append(entries, entry)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// optionalTime returns a pointer to t or nil if t is the zero time, for omitting unset times from JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,69 @@
+package czds
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// quotaTracker accumulates the zone data downloaded by a Client over its lifetime
+type quotaTracker struct {
+	bytes atomic.Int64
+	zones atomic.Int64
+}
+
+// DownloadedBytes returns the total number of zone file and DoRaw() response bytes the client has transferred since it was created
+// or ResetDownloadStats() was last called. Every byte received is counted, as it still counts towards any transfer quota,
+// including attempts that failed and were retried, downloads that failed, and parts of ranged downloads that were
+// discarded and downloaded again. Use SummarizeResults() for the size of the zones that were saved
+func (c *Client) DownloadedBytes() int64 {
+	return c.quota.bytes.Load()
+}
+
+// DownloadedZones returns the number of zone files the client has downloaded successfully since it was created
+// or ResetDownloadStats() was last called. Zones skipped as not modified are not counted
+func (c *Client) DownloadedZones() int64 {
+	return c.quota.zones.Load()
+}
+
+// ResetDownloadStats resets the counts returned by DownloadedBytes() and DownloadedZones() to zero
+func (c *Client) ResetDownloadStats() {
+	c.quota.bytes.Store(0)
+	c.quota.zones.Store(0)
+}
+
+// countingReader adds the number of bytes read from r to n
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+// DownloadSummary totals the results of a batch of zone downloads
+type DownloadSummary struct {
+	Downloaded  int   // zones downloaded successfully
+	NotModified int   // zones skipped because the local copy was up to date
+	Failed      int   // zones that failed to download
+	Bytes       int64 // size of the zones downloaded successfully, not including retries or zones that were not modified
+}
+
+// SummarizeResults totals the results returned by DownloadZones() and the other batch download methods
+func SummarizeResults(results []ZoneResult) DownloadSummary {
+	var summary DownloadSummary
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			summary.Failed++
+		case result.NotModified:
+			summary.NotModified++
+		default:
+			summary.Downloaded++
+			summary.Bytes += result.Size
+		}
+	}
+	return summary
+}
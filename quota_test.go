@@ -0,0 +1,43 @@
+package czds
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadedBytesCountsRetries(t *testing.T) {
+	zone := []byte("example.\t86400\tin\tns\tns1.example.\n")
+	var attempts atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(zone)))
+		if attempts.Add(1) == 1 {
+			// cut the first attempt short after half the zone
+			w.Write(zone[:len(zone)/2])
+			return
+		}
+		w.Write(zone)
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}
+
+	job := zoneJob{link: client.BaseURL + "/czds/downloads/example.zone"}
+	result := client.downloadLink(context.Background(), t.TempDir(), job, &DownloadOptions{})
+	if result.Err != nil {
+		t.Fatal(result.Err)
+	}
+	if attempts.Load() != 2 {
+		t.Fatalf("made %d attempts, want 2", attempts.Load())
+	}
+	if got, want := client.DownloadedBytes(), int64(len(zone)+len(zone)/2); got != want {
+		t.Errorf("DownloadedBytes() = %d, want %d", got, want)
+	}
+	if got := client.DownloadedZones(); got != 1 {
+		t.Errorf("DownloadedZones() = %d, want 1", got)
+	}
+	if summary := SummarizeResults([]ZoneResult{result}); summary.Bytes != int64(len(zone)) {
+		t.Errorf("summary counted %d bytes, want %d", summary.Bytes, len(zone))
+	}
+}
@@ -0,0 +1,141 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// errRangeIgnored is returned by a part download when the server responds with the whole zone
+var errRangeIgnored = errors.New("server ignored range request")
+
+// DownloadZoneParallel downloads the zone for tld into f by splitting it into parts byte ranges downloaded concurrently
+// this can speed up downloading single very large zones over high latency links.
+// If the server does not support range requests the zone is downloaded as a single stream instead.
+// f is truncated to the zone's size and the total length written is verified
+func (c *Client) DownloadZoneParallel(ctx context.Context, tld string, f *os.File, parts int) error {
+	if c.CheckPrivateData {
+		err := c.privateDataError(ctx, tld)
+		if err != nil {
+			return err
+		}
+	}
+	ctx, cancel := c.downloadContext(ctx)
+	defer cancel()
+	err := c.downloadZoneParallel(ctx, c.ZoneLink(tld), f, parts)
+	if err != nil {
+		return err
+	}
+	c.quota.zones.Add(1)
+	return nil
+}
+
+// downloadZoneParallel downloads the zone at link into f using up to parts ranged requests at once
+func (c *Client) downloadZoneParallel(ctx context.Context, link string, f *os.File, parts int) error {
+	resp, err := c.apiRequest(ctx, true, "HEAD", link, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil || size < 1 || parts < 2 || resp.Header.Get("Accept-Ranges") != "bytes" {
+		return c.downloadZoneStream(ctx, link, f, -1)
+	}
+	if int64(parts) > size {
+		parts = int(size)
+	}
+
+	err = f.Truncate(size)
+	if err != nil {
+		return err
+	}
+
+	partCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	partSize := (size + int64(parts) - 1) / int64(parts)
+	errs := make([]error, parts)
+	var wg sync.WaitGroup
+	for i := 0; i < parts; i++ {
+		start := int64(i) * partSize
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		wg.Add(1)
+		go func(i int, start, end int64) {
+			defer wg.Done()
+			errs[i] = c.downloadZoneRange(partCtx, link, f, start, end)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if errors.Is(err, errRangeIgnored) {
+			return c.downloadZoneStream(ctx, link, f, size)
+		}
+	}
+	// report the error that caused the cancellation rather than the cancellations
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadZoneRange downloads bytes start through end inclusive of the zone at link into f at the same offset
+func (c *Client) downloadZoneRange(ctx context.Context, link string, f *os.File, start, end int64) error {
+	resp, err := c.apiRequest(ctx, true, "GET", link, nil, WithRequestHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return errRangeIgnored
+	}
+
+	n, err := io.Copy(io.NewOffsetWriter(f, start), c.downloadReader(ctx, resp.Body))
+	if err != nil {
+		return err
+	}
+	if want := end - start + 1; n != want {
+		return fmt.Errorf("range %d-%d of %s returned %d bytes, expected %d", start, end, link, n, want)
+	}
+	return nil
+}
+
+// downloadZoneStream downloads the whole zone at link into f from the start of the file
+// if size is not negative the number of bytes downloaded must match it
+func (c *Client) downloadZoneStream(ctx context.Context, link string, f *os.File, size int64) error {
+	resp, err := c.apiRequest(ctx, true, "GET", link, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(io.NewOffsetWriter(f, 0), c.downloadReader(ctx, resp.Body))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s was empty", link)
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("%s returned %d bytes, expected %d", link, n, size)
+	}
+	return f.Truncate(n)
+}
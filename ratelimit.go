@@ -0,0 +1,125 @@
+package czds
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a token bucket rate limiter safe for concurrent use
+// it refills at rate tokens per second up to a burst of one second worth of tokens
+// callers may take more tokens than are available and wait for the bucket to refill the debt,
+// which keeps the long term rate correct for any mix of request sizes
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full tokenBucket that refills at rate tokens per second
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens from the bucket, blocking until they have been refilled or ctx is done
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	return sleepContext(ctx, delay)
+}
+
+// chunk returns the largest read size that should be made against the bucket at once
+func (b *tokenBucket) chunk() int {
+	return int(b.burst)
+}
+
+// RateLimitedReader is an io.Reader that limits the rate data can be read from the underlying reader
+// readers from Client.RateLimitedReader() share the Client's WithDownloadBandwidth limit with its own downloads.
+// The zero value reads nothing, and a reader without a limit reads at full speed
+type RateLimitedReader struct {
+	ctx    context.Context
+	r      io.Reader
+	bucket *tokenBucket
+}
+
+// NewRateLimitedReader returns a RateLimitedReader that reads from r at up to bytesPerSec
+func NewRateLimitedReader(r io.Reader, bytesPerSec int64) *RateLimitedReader {
+	return NewRateLimitedReaderContext(context.Background(), r, bytesPerSec)
+}
+
+// NewRateLimitedReaderContext returns a RateLimitedReader that reads from r at up to bytesPerSec
+// reads return ctx.Err() once ctx is done, including while waiting for the rate limit
+func NewRateLimitedReaderContext(ctx context.Context, r io.Reader, bytesPerSec int64) *RateLimitedReader {
+	if bytesPerSec < 1 {
+		bytesPerSec = 1
+	}
+	return &RateLimitedReader{
+		ctx:    ctx,
+		r:      r,
+		bucket: newTokenBucket(float64(bytesPerSec)),
+	}
+}
+
+// RateLimitedReader returns a RateLimitedReader that reads from r under the Client's WithDownloadBandwidth limit,
+// shared with the Client's own downloads, so custom download paths keep to the same cap.
+// Reads are not limited if the Client has no bandwidth limit, and return ctx.Err() once ctx is done
+func (c *Client) RateLimitedReader(ctx context.Context, r io.Reader) *RateLimitedReader {
+	return &RateLimitedReader{
+		ctx:    ctx,
+		r:      r,
+		bucket: c.downloadLimiter,
+	}
+}
+
+func (r *RateLimitedReader) Read(p []byte) (int, error) {
+	if r.r == nil {
+		return 0, io.EOF
+	}
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.bucket == nil {
+		return r.r.Read(p)
+	}
+	if chunk := r.bucket.chunk(); len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.bucket.wait(ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
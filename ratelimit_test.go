@@ -0,0 +1,87 @@
+package czds
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitedReader(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 300)
+	start := time.Now()
+	got, err := io.ReadAll(NewRateLimitedReader(bytes.NewReader(data), 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("read %d bytes, want %d", len(got), len(data))
+	}
+	// the first second is the burst, so a full read under the burst is not delayed
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("reading within the burst took %s", elapsed)
+	}
+
+	data = bytes.Repeat([]byte("x"), 1500)
+	start = time.Now()
+	_, err = io.ReadAll(NewRateLimitedReader(bytes.NewReader(data), 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("reading 1500 bytes at 1000/s took only %s", elapsed)
+	}
+}
+
+func TestNewRateLimitedReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewRateLimitedReaderContext(ctx, bytes.NewReader([]byte("zone")), 1000).Read(make([]byte, 4))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRateLimitedReaderZeroValue(t *testing.T) {
+	n, err := (&RateLimitedReader{}).Read(make([]byte, 4))
+	if n != 0 || err != io.EOF {
+		t.Errorf("zero value read %d bytes with error %v, want io.EOF", n, err)
+	}
+	got, err := io.ReadAll(&RateLimitedReader{r: bytes.NewReader([]byte("zone"))})
+	if err != nil || string(got) != "zone" {
+		t.Errorf("unlimited reader read %q with error %v", got, err)
+	}
+}
+
+func TestClientRateLimitedReaderSharesLimit(t *testing.T) {
+	client := NewClient("user", "password", WithDownloadBandwidth(1000))
+	if client.downloadLimiter == nil {
+		t.Fatal("no download limiter")
+	}
+	// two readers of 750 bytes share the 1000 byte burst, so together they must wait for the bucket
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := io.ReadAll(client.RateLimitedReader(context.Background(), bytes.NewReader(make([]byte, 750))))
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("reading 1500 bytes through the shared limit of 1000/s took only %s", elapsed)
+	}
+
+	unlimited := NewClient("user", "password")
+	got, err := io.ReadAll(unlimited.RateLimitedReader(context.Background(), bytes.NewReader([]byte("zone"))))
+	if err != nil || string(got) != "zone" {
+		t.Errorf("unlimited client read %q with error %v", got, err)
+	}
+}
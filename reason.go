@@ -0,0 +1,137 @@
+package czds
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default bounds on the length of RequestSubmission.Reason in characters
+// Note: these match the limits observed from the portal and are unverified against the API
+const (
+	DefaultMinReasonLength = 1
+	DefaultMaxReasonLength = 512
+)
+
+// ReasonLengthError is returned when submitting a request whose reason is shorter or longer than allowed
+type ReasonLengthError struct {
+	Length int // length of the reason in characters, ignoring leading and trailing whitespace
+	Min    int
+	Max    int
+}
+
+func (e *ReasonLengthError) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf("request reason is %d characters, must be between %d and %d", e.Length, e.Min, e.Max)
+	}
+	return fmt.Sprintf("request reason is %d characters, must be at least %d", e.Length, e.Min)
+}
+
+// reasonLengthBounds returns the client's allowed reason lengths, a max of 0 is unlimited
+func (c *Client) reasonLengthBounds() (minLength, maxLength int) {
+	minLength, maxLength = c.MinReasonLength, c.MaxReasonLength
+	if minLength == 0 {
+		minLength = DefaultMinReasonLength
+	}
+	if maxLength == 0 {
+		maxLength = DefaultMaxReasonLength
+	}
+	if maxLength < 0 {
+		maxLength = 0
+	}
+	return minLength, maxLength
+}
+
+// ReasonValidator inspects a request reason before it is submitted and returns warnings about it
+// warnings do not stop the submission, they are logged to the client's Logger and returned by ReasonWarnings()
+type ReasonValidator func(reason string) []string
+
+// reasonShortWarning is the length in characters below which DefaultReasonValidator warns
+const reasonShortWarning = 20
+
+// genericReasons are reasons that do not describe a purpose, compared lowercase without punctuation
+var genericReasons = map[string]bool{
+	"test":      true,
+	"testing":   true,
+	"research":  true,
+	"reason":    true,
+	"none":      true,
+	"na":        true,
+	"access":    true,
+	"zone file": true,
+	"zone data": true,
+	"dns":       true,
+	"security":  true,
+}
+
+// placeholderWords are words found in reasons that were never filled in, compared lowercase
+var placeholderWords = map[string]bool{
+	"todo":        true,
+	"tbd":         true,
+	"fixme":       true,
+	"changeme":    true,
+	"placeholder": true,
+	"asdf":        true,
+	"lorem":       true,
+}
+
+// placeholderMarkers are substrings found in template reasons, such as "<your reason here>"
+var placeholderMarkers = []string{"<", ">"}
+
+// DefaultReasonValidator warns about reasons ICANN commonly rejects for not describing a legitimate purpose:
+// reasons that are very short, generic such as "test", or look like unfilled placeholders.
+// It can not predict ICANN's review, a reason without warnings may still be rejected
+func DefaultReasonValidator(reason string) []string {
+	var warnings []string
+	trimmed := strings.TrimSpace(reason)
+	lower := strings.ToLower(trimmed)
+	if length := utf8.RuneCountInString(trimmed); length < reasonShortWarning {
+		warnings = append(warnings, fmt.Sprintf("reason is only %d characters, describe how the zone data will be used", length))
+	}
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if genericReasons[strings.Join(words, " ")] {
+		warnings = append(warnings, fmt.Sprintf("reason %q is generic, describe how the zone data will be used", trimmed))
+	}
+	if marker, ok := placeholderMarker(lower, words); ok {
+		warnings = append(warnings, fmt.Sprintf("reason contains %q and looks like a placeholder", marker))
+	}
+	return warnings
+}
+
+// placeholderMarker returns the first placeholder word or marker found in reason and its words
+func placeholderMarker(reason string, words []string) (string, bool) {
+	for _, word := range words {
+		if placeholderWords[word] {
+			return word, true
+		}
+	}
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(reason, marker) {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// ReasonWarnings returns the warnings the client's ReasonValidator has about reason, such as it being too generic
+// use this to show them before submitting, they are also logged when a request is submitted
+func (c *Client) ReasonWarnings(reason string) []string {
+	validator := c.ReasonValidator
+	if validator == nil {
+		validator = DefaultReasonValidator
+	}
+	return validator(reason)
+}
+
+// validateReason checks that reason is within the client's allowed reason lengths
+func (c *Client) validateReason(reason string) error {
+	minLength, maxLength := c.reasonLengthBounds()
+	length := utf8.RuneCountInString(strings.TrimSpace(reason))
+	if length < minLength || (maxLength > 0 && length > maxLength) {
+		return &ReasonLengthError{Length: length, Min: minLength, Max: maxLength}
+	}
+	return nil
+}
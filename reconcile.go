@@ -0,0 +1,90 @@
+package czds
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ReconcileResult describes the actions taken by Reconcile()
+// all lists are de-duplicated and sorted alphabetically by A-label
+type ReconcileResult struct {
+	Requested       []string // desired TLDs a request was submitted for
+	AlreadyApproved []string // desired TLDs the account already has access to
+	AlreadyPending  []string // desired TLDs with a request already submitted or pending
+	NotInCatalog    []string // desired TLDs that are not in the catalog and could not be requested
+	// Unwanted are TLDs the account has access to or has requested that are not desired
+	// the API does not support removing access so these are only reported for manual cleanup
+	Unwanted []string
+}
+
+// Reconcile brings the account's access in line with the desired list of TLDs by submitting requests with reason
+// for every desired TLD that is not already approved or pending, in chunks with BatchSubmitRequest().
+// TLDs with access that are no longer desired are reported in ReconcileResult.Unwanted but left untouched.
+// If submitting fails the result so far is returned with the error
+func (c *Client) Reconcile(desired []string, reason string) (*ReconcileResult, error) {
+	ctx := context.Background()
+	status, err := c.GetTLDStatus()
+	if err != nil {
+		return nil, err
+	}
+	checks, err := c.checkRequestable(ctx, status, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{
+		Requested:       make([]string, 0),
+		AlreadyApproved: make([]string, 0),
+		AlreadyPending:  make([]string, 0),
+		NotInCatalog:    make([]string, 0),
+		Unwanted:        make([]string, 0),
+	}
+	wanted := make(map[string]bool, len(checks))
+	missing := make([]string, 0)
+	for _, check := range checks {
+		if wanted[check.TLD] {
+			continue
+		}
+		wanted[check.TLD] = true
+		switch check.Reason {
+		case NotRequestableApproved:
+			result.AlreadyApproved = append(result.AlreadyApproved, check.TLD)
+		case NotRequestablePending:
+			result.AlreadyPending = append(result.AlreadyPending, check.TLD)
+		case NotRequestableNotInCatalog:
+			result.NotInCatalog = append(result.NotInCatalog, check.TLD)
+		default:
+			missing = append(missing, check.TLD)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, tld := range status {
+		name := strings.ToLower(tld.TLD)
+		if seen[name] || wanted[name] {
+			continue
+		}
+		seen[name] = true
+		switch tld.CurrentStatus {
+		case StatusApproved, StatusSubmitted, StatusPending:
+			result.Unwanted = append(result.Unwanted, name)
+		}
+	}
+	sort.Strings(result.AlreadyApproved)
+	sort.Strings(result.AlreadyPending)
+	sort.Strings(result.NotInCatalog)
+	sort.Strings(result.Unwanted)
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+	request := &RequestSubmission{
+		TLDNames: missing,
+		Reason:   reason,
+	}
+	submitted, err := c.BatchSubmitRequest(ctx, request, nil)
+	if submitted != nil {
+		result.Requested = submitted.Submitted
+	}
+	return result, err
+}
@@ -0,0 +1,105 @@
+package czds
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRenewalCheckInterval is how often RunAutoRenewal checks for expiring requests when checkInterval is not set
+const DefaultRenewalCheckInterval = 24 * time.Hour
+
+// RunAutoRenewal keeps the account's access from lapsing by checking its requests every checkInterval and
+// submitting a new request with reason for every TLD whose approved access expires within DefaultRenewBefore
+// or has already expired. The API has no way to ask for automatic renewal when submitting a request,
+// so this renews from the client side and must be left running. TLDs with a request already submitted
+// or pending are skipped so renewals are never submitted twice. Checks that fail with a transient error
+// are logged and retried at the next interval, other errors stop the renewal and are returned.
+// Runs until ctx is done
+func (c *Client) RunAutoRenewal(ctx context.Context, reason string, checkInterval time.Duration) error {
+	if checkInterval <= 0 {
+		checkInterval = DefaultRenewalCheckInterval
+	}
+	for {
+		renewed, err := c.renewExpiring(ctx, reason)
+		if err != nil && !IsTransientError(err) {
+			return err
+		}
+		if err != nil {
+			c.logf(ctx, "auto renewal check failed, retrying in %s: %s", checkInterval, err)
+		} else if len(renewed) > 0 {
+			c.logf(ctx, "auto renewal requested %d TLDs: %s", len(renewed), strings.Join(renewed, ", "))
+		}
+
+		err = sleepContext(ctx, jitterDuration(checkInterval, DefaultWaitJitter))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// renewExpiring submits a request for every TLD whose active request is approved and expiring soon or has expired
+// returns the TLDs submitted sorted alphabetically
+func (c *Client) renewExpiring(ctx context.Context, reason string) ([]string, error) {
+	groups, err := c.groupRequestsByTLD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	expiring := make([]string, 0)
+	for tld, requests := range groups {
+		active, ok := ActiveRequest(requests)
+		if !ok || !active.IsTerminal() {
+			continue
+		}
+		approved := strings.EqualFold(active.Status, RequestApproved)
+		if (approved && active.ExpiringSoonAt(DefaultRenewBefore, now)) || strings.EqualFold(active.Status, RequestExpired) {
+			expiring = append(expiring, tld)
+		}
+	}
+	if len(expiring) == 0 {
+		return expiring, nil
+	}
+	sort.Strings(expiring)
+
+	result, err := c.BatchSubmitRequest(ctx, &RequestSubmission{
+		TLDNames: expiring,
+		Reason:   reason,
+	}, nil)
+	if result == nil {
+		return nil, err
+	}
+	return result.Submitted, err
+}
+
+// WarnExpiringDuringRun returns the approved requests whose access expires within estimatedDuration from now,
+// soonest first, and logs a warning for each. Call this before a long mirror run to renew access that would
+// otherwise lapse partway through and fail the remaining downloads. Requests that have already expired are included
+func (c *Client) WarnExpiringDuringRun(estimatedDuration time.Duration) ([]Request, error) {
+	ctx := context.Background()
+	approved, err := c.GetAllRequests(ctx, &RequestsFilter{
+		Status: RequestApproved,
+		Sort: RequestsSort{
+			Field:     SortByExpiration,
+			Direction: SortAsc,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	expiring := make([]Request, 0)
+	for _, request := range approved {
+		if request.ExpiringSoonAt(estimatedDuration, now) {
+			expiring = append(expiring, request)
+		}
+	}
+	sort.SliceStable(expiring, func(i, j int) bool {
+		return expiring[i].Expired.Before(expiring[j].Expired)
+	})
+	for _, request := range expiring {
+		c.logf(ctx, "warning: access to %s expires %s, during the estimated run", request.TLD, request.Expired.Format(time.RFC3339))
+	}
+	return expiring, nil
+}
@@ -0,0 +1,177 @@
+package czds
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestReportRow is a single row of the requests report from StreamAllRequestsReport()
+// mapping the report's column headers to the row's values
+type RequestReportRow map[string]string
+
+// StreamAllRequestsReport downloads the same report as DownloadAllRequests() and calls fn with each row
+// as it is read so the report is never held in memory. Iteration stops at the first error returned by fn
+func (c *Client) StreamAllRequestsReport(ctx context.Context, fn func(row RequestReportRow) error) error {
+	url := c.BaseURL + "/czds/requests/report"
+	resp, err := c.apiRequest(ctx, true, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	reader := csv.NewReader(resp.Body)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%s was empty", url)
+	}
+	if err != nil {
+		return err
+	}
+	reader.ReuseRecord = true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := make(RequestReportRow, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		err = fn(row)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// GetAllRequestsReport parses the report from DownloadAllRequests() into a slice of rows
+func (c *Client) GetAllRequestsReport(ctx context.Context) ([]RequestReportRow, error) {
+	rows := make([]RequestReportRow, 0, DefaultPageSize)
+	err := c.StreamAllRequestsReport(ctx, func(row RequestReportRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// reportTimeLayouts are the timestamp formats NormalizeReport() recognizes in report values
+var reportTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	time.UnixDate,
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// NormalizeReport returns a canonical copy of the report rows so two reports can be compared deterministically
+// values are trimmed, timestamps are rewritten as RFC 3339 in UTC with the Unix epoch, used by the report for
+// unset times, as empty, and the rows are sorted. rows is not modified
+func NormalizeReport(rows []RequestReportRow) []RequestReportRow {
+	normalized := make([]RequestReportRow, len(rows))
+	for n, row := range rows {
+		canonical := make(RequestReportRow, len(row))
+		for column, value := range row {
+			canonical[column] = normalizeReportValue(value)
+		}
+		normalized[n] = canonical
+	}
+	// sort by each row's formatted columns, computed once per row
+	formatted := make([]string, len(normalized))
+	for n, row := range normalized {
+		formatted[n] = formatReportRow(row)
+	}
+	sort.Sort(reportRowSorter{rows: normalized, keys: formatted})
+	return normalized
+}
+
+// reportRowSorter sorts rows by their formatted keys
+type reportRowSorter struct {
+	rows []RequestReportRow
+	keys []string
+}
+
+func (s reportRowSorter) Len() int           { return len(s.rows) }
+func (s reportRowSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s reportRowSorter) Swap(i, j int) {
+	s.rows[i], s.rows[j] = s.rows[j], s.rows[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// normalizeReportValue trims value and rewrites it in the canonical layout if it is a timestamp
+func normalizeReportValue(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range reportTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if t.Unix() == 0 {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return value
+}
+
+// formatReportRow formats row as its columns and values in column order
+func formatReportRow(row RequestReportRow) string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	var b strings.Builder
+	for n, column := range columns {
+		if n > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s=%q", column, row[column])
+	}
+	return b.String()
+}
+
+// CompareReports compares two requests reports after normalizing them with NormalizeReport()
+// and returns a human readable diff, one line per row: "- " for rows only in oldRows, "+ " for rows only in newRows.
+// The diff is empty if the reports are the same, ignoring row order
+func CompareReports(oldRows, newRows []RequestReportRow) string {
+	counts := make(map[string]int)
+	for _, row := range NormalizeReport(oldRows) {
+		counts[formatReportRow(row)]--
+	}
+	for _, row := range NormalizeReport(newRows) {
+		counts[formatReportRow(row)]++
+	}
+	lines := make([]string, 0)
+	for row, count := range counts {
+		prefix := "+ "
+		if count < 0 {
+			prefix = "- "
+			count = -count
+		}
+		for ; count > 0; count-- {
+			lines = append(lines, prefix+row)
+		}
+	}
+	// removed rows first, then each group in row order
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i][0] != lines[j][0] {
+			return lines[i][0] == '-'
+		}
+		return lines[i] < lines[j]
+	})
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,124 @@
+package czds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reasons for RequestabilityResult.Reason when a TLD can not be requested
+const (
+	NotRequestableApproved     = "access is already approved"
+	NotRequestablePending      = "a request is already submitted or pending"
+	NotRequestableNotInCatalog = "not in the catalog of zones"
+)
+
+// RequestabilityResult describes whether a TLD can currently be requested, from CheckRequestable()
+type RequestabilityResult struct {
+	TLD         string // A-label of the TLD, lower case
+	Requestable bool
+	Reason      string    // why the TLD can not be requested, one of the NotRequestable* constants, empty if Requestable
+	Status      string    // TLDStatus.CurrentStatus from the catalog, empty if not in the catalog
+	RequestID   string    // id of the account's active request for the TLD, if any
+	Expired     time.Time // expiration of the account's active request, the zero time if none or not set
+	// SFTP is true when the zone is only delivered over SFTP. These TLDs can still be requested
+	// but once approved their zones can not be downloaded over HTTPS by this package
+	SFTP bool
+}
+
+// CheckRequestable reports whether each of tlds can currently be requested by the account and why not if not
+// the catalog from GetTLDStatus() and all of the account's requests are fetched once and used for every TLD.
+// The returned map is keyed by the TLDs as they were provided
+func (c *Client) CheckRequestable(tlds []string) (map[string]RequestabilityResult, error) {
+	status, err := c.GetTLDStatus()
+	if err != nil {
+		return nil, err
+	}
+	return c.checkRequestable(context.Background(), status, tlds)
+}
+
+// checkRequestable evaluates whether each of tlds can be requested given the catalog status and the account's requests
+func (c *Client) checkRequestable(ctx context.Context, status []TLDStatus, tlds []string) (map[string]RequestabilityResult, error) {
+	catalog := make(map[string]TLDStatus, len(status))
+	for _, tld := range status {
+		catalog[strings.ToLower(tld.TLD)] = tld
+	}
+
+	groups, err := c.groupRequestsByTLD(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]Request, len(groups))
+	for tld, requests := range groups {
+		if request, ok := ActiveRequest(requests); ok {
+			active[strings.ToLower(tld)] = request
+		}
+	}
+
+	now := c.now()
+	results := make(map[string]RequestabilityResult, len(tlds))
+	for _, input := range tlds {
+		tld := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(input), "."))
+		result := RequestabilityResult{TLD: tld}
+		entry, inCatalog := catalog[tld]
+		if inCatalog {
+			result.Status = entry.CurrentStatus
+			result.SFTP = entry.SFTP
+		}
+		request, hasRequest := active[tld]
+		if hasRequest {
+			result.RequestID = request.RequestID
+			result.Expired = request.Expired
+		}
+
+		switch {
+		case !inCatalog:
+			result.Reason = NotRequestableNotInCatalog
+		case entry.CurrentStatus == StatusSubmitted || entry.CurrentStatus == StatusPending || (hasRequest && !request.IsTerminal()):
+			result.Reason = NotRequestablePending
+		case entry.CurrentStatus == StatusApproved || (hasRequest && strings.EqualFold(request.Status, RequestApproved) && !request.ExpiredAt(now)):
+			result.Reason = NotRequestableApproved
+		default:
+			result.Requestable = true
+		}
+		results[input] = result
+	}
+	return results, nil
+}
+
+// DefaultRenewBefore is how close to expiring an approved request must be for SubmitRequestIfNotApproved() to submit a new one
+const DefaultRenewBefore = 30 * 24 * time.Hour
+
+// SubmitRequestIfNotApproved submits a request for tld with reason only if the account does not already have access,
+// returning whether a request was submitted. Nothing is submitted if a request is already submitted or pending,
+// or if access is approved and does not expire within DefaultRenewBefore.
+// Returns ErrNotFound if tld is not in the catalog
+func (c *Client) SubmitRequestIfNotApproved(tld, reason string) (submitted bool, err error) {
+	results, err := c.CheckRequestable([]string{tld})
+	if err != nil {
+		return false, err
+	}
+	result := results[tld]
+	switch result.Reason {
+	case NotRequestableNotInCatalog:
+		return false, fmt.Errorf("%w: %s is not in the catalog", ErrNotFound, tld)
+	case NotRequestablePending:
+		return false, nil
+	case NotRequestableApproved:
+		renewing := Request{Expired: result.Expired}
+		if !renewing.ExpiringSoonAt(DefaultRenewBefore, c.now()) {
+			return false, nil
+		}
+	}
+
+	request := &RequestSubmission{
+		TLDNames: []string{result.TLD},
+		Reason:   reason,
+	}
+	err = c.submitWithCurrentTerms(context.Background(), request)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,8 +1,16 @@
 package czds
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -44,6 +52,19 @@ const (
 	StatusRevoked   = "revoked" // unverified
 )
 
+// IsTerminalStatus reports whether status is a final state for a request that will not change
+// without further action from the requester.
+// Approved, Denied, Expired and Revoked are terminal; Submitted and Pending are transient.
+// Both the Request* and Status* constants are accepted, compared case-insensitively.
+// Note: the revoked status string is unverified against the live API
+func IsTerminalStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case StatusApproved, StatusDenied, StatusExpired, StatusRevoked:
+		return true
+	}
+	return false
+}
+
 // RequestsFilter is used to set what results should be returned by GetRequests
 type RequestsFilter struct {
 	Status     string             `json:"status"` // should be set to one of the Request* constants
@@ -72,10 +93,15 @@ type Request struct {
 	Status      string    `json:"status"` // should be set to one of the Request* constants
 	Created     time.Time `json:"created"`
 	LastUpdated time.Time `json:"last_updated"`
-	Expired     time.Time `json:"expired"` // Note: epoch 0 means no expiration set
+	Expired     time.Time `json:"expired"` // Note: the zero time means no expiration set
 	SFTP        bool      `json:"sftp"`
 }
 
+// IsTerminal reports whether the request is in a terminal state, see IsTerminalStatus()
+func (r *Request) IsTerminal() bool {
+	return IsTerminalStatus(r.Status)
+}
+
 // RequestsResponse holds Requests from from GetRequests() and total number of requests that match the query but may not be returned due to pagination
 type RequestsResponse struct {
 	Requests      []Request `json:"requests"`
@@ -113,12 +139,31 @@ type RequestsInfo struct {
 	RequestIP        string         `json:"requestIp"`
 	Reason           string         `json:"reason"`
 	LastUpdated      time.Time      `json:"last_updated"`
-	Expired          time.Time      `json:"expired"` // Note: epoch 0 means no expiration set
+	Expired          time.Time      `json:"expired"` // Note: the zero time means no expiration set
 	History          []HistoryEntry `json:"history"`
 	FtpDetails       *FtpDetails    `json:"ftpDetails"`
 	PrivateDataError bool           `json:"privateDataError"`
 }
 
+// IsDownloadAllowedFrom reports whether ip is in the request's FtpIps allowlist
+// entries may be single IPv4 or IPv6 addresses or CIDR ranges. An empty allowlist allows no addresses
+func (ri *RequestsInfo) IsDownloadAllowedFrom(ip net.IP) bool {
+	for _, entry := range ri.FtpIps {
+		_, network, err := net.ParseCIDR(entry)
+		if err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		allowed := net.ParseIP(entry)
+		if allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequestSubmission contains the information required to submit a new request with SubmitRequest()
 type RequestSubmission struct {
 	AllTLDs          bool     `json:"allTlds"`
@@ -128,6 +173,30 @@ type RequestSubmission struct {
 	AdditionalFTPIps []string `json:"additionalFtfIps,omitempty"`
 }
 
+// NormalizeFTPIPs validates and normalizes IP addresses and CIDR ranges for RequestSubmission.AdditionalFTPIps
+// IPv6 addresses are compressed and lowercased and CIDR ranges have their host bits cleared.
+// Returns an error naming the first entry that is neither an address nor a CIDR range
+func NormalizeFTPIPs(entries []string) ([]string, error) {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		trimmed := strings.TrimSpace(entry)
+		if strings.Contains(trimmed, "/") {
+			prefix, err := netip.ParsePrefix(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("invalid additional FTP IP range %q: %w", entry, err)
+			}
+			normalized = append(normalized, prefix.Masked().String())
+			continue
+		}
+		addr, err := netip.ParseAddr(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid additional FTP IP %q: %w", entry, err)
+		}
+		normalized = append(normalized, addr.String())
+	}
+	return normalized, nil
+}
+
 // Terms holds the terms and conditions details from GetTerms()
 type Terms struct {
 	Version    string    `json:"version"`
@@ -138,24 +207,167 @@ type Terms struct {
 
 // GetRequests searches for the status of zones requests as seen on the
 // CZDS dashboard page "https://czds.icann.org/zone-requests/all"
+// TotalRequests is the number of requests matching the filter even when the page requested is past the last one,
+// so an empty page can be told apart from a filter that matched nothing
 func (c *Client) GetRequests(filter *RequestsFilter) (*RequestsResponse, error) {
+	ctx := context.Background()
+	requests, err := c.getRequests(ctx, filter)
+	if err != nil {
+		return requests, err
+	}
+	requests.TotalRequests, err = c.pageTotal(ctx, filter, len(requests.Requests), requests.TotalRequests)
+	return requests, err
+}
+
+// pageTotal returns the total number of requests matching filter for a page of count requests reporting total
+// the total is not reported for pages past the last one, so an empty page with no total is counted using the first page
+func (c *Client) pageTotal(ctx context.Context, filter *RequestsFilter, count int, total int64) (int64, error) {
+	if count > 0 || total > 0 || filter == nil || filter.Pagination.Page < 1 {
+		return total, nil
+	}
+	f := *filter
+	f.Pagination = RequestsPagination{Size: 1}
+	requests, err := c.getRequests(ctx, &f)
+	if err != nil {
+		return 0, err
+	}
+	return requests.TotalRequests, nil
+}
+
+// getRequests performs the request for GetRequests
+func (c *Client) getRequests(ctx context.Context, filter *RequestsFilter) (*RequestsResponse, error) {
 	requests := new(RequestsResponse)
-	err := c.jsonAPI("POST", "/czds/requests/all", filter, requests)
+	err := c.jsonAPI(ctx, "POST", "/czds/requests/all", c.clampPageSize(ctx, filter), requests)
 	return requests, err
 }
 
+// MaxPageSize is the largest RequestsPagination.Size requested, larger sizes are clamped to it and a warning is logged
+// as the API may silently return fewer requests than asked for. Use ForEachRequest() to reliably get every request
+// Note: this is the largest page size observed to be honored and is unverified against the API
+const MaxPageSize = 1000
+
+// clampPageSize returns filter with its page size clamped to MaxPageSize, logging a warning if it was changed
+func (c *Client) clampPageSize(ctx context.Context, filter *RequestsFilter) *RequestsFilter {
+	if filter == nil || filter.Pagination.Size <= MaxPageSize {
+		return filter
+	}
+	c.logf(ctx, "warning: page size %d is larger than the maximum of %d, clamping", filter.Pagination.Size, MaxPageSize)
+	clamped := *filter
+	clamped.Pagination.Size = MaxPageSize
+	return &clamped
+}
+
+// RequestsLastUpdated returns the newest LastUpdated time of all of the account's requests by fetching a single
+// page of one request, as a cheap check for whether anything changed since a previous sync.
+// Returns the zero time if the account has no requests
+func (c *Client) RequestsLastUpdated() (time.Time, error) {
+	requests, err := c.getRequests(context.Background(), &RequestsFilter{
+		Status: RequestAll,
+		Sort: RequestsSort{
+			Field:     SortByLastUpdated,
+			Direction: SortDesc,
+		},
+		Pagination: RequestsPagination{
+			Size: 1,
+		},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(requests.Requests) == 0 {
+		return time.Time{}, nil
+	}
+	return requests.Requests[0].LastUpdated, nil
+}
+
+// GetRequestsLean is like GetRequests() but drops each request's ULabel and trims the results to their exact size
+// to reduce the memory retained by services holding large numbers of requests.
+// The API does not support selecting fields so complete requests are still transferred and decoded
+func (c *Client) GetRequestsLean(filter *RequestsFilter) (*RequestsResponse, error) {
+	requests, err := c.GetRequests(filter)
+	if err != nil {
+		return requests, err
+	}
+	lean := make([]Request, len(requests.Requests))
+	for i, request := range requests.Requests {
+		request.ULabel = ""
+		lean[i] = request
+	}
+	requests.Requests = lean
+	return requests, nil
+}
+
+// RequestDecodeError holds a request returned by GetRequestsTolerant() that could not be decoded
+type RequestDecodeError struct {
+	Raw json.RawMessage // the request's JSON as sent by the server
+	Err error
+}
+
+func (e *RequestDecodeError) Error() string {
+	return fmt.Sprintf("unable to decode request %s: %s", e.Raw, e.Err)
+}
+
+func (e *RequestDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// GetRequestsTolerant is like GetRequests() but decodes each request individually so a malformed request
+// does not fail the whole page. Requests that could not be decoded are returned as RequestDecodeErrors
+// and left out of the RequestsResponse
+func (c *Client) GetRequestsTolerant(filter *RequestsFilter) (*RequestsResponse, []RequestDecodeError, error) {
+	var raw struct {
+		Requests      []json.RawMessage `json:"requests"`
+		TotalRequests int64             `json:"totalRequests"`
+	}
+	ctx := context.Background()
+	err := c.jsonAPI(ctx, "POST", "/czds/requests/all", c.clampPageSize(ctx, filter), &raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total, err := c.pageTotal(ctx, filter, len(raw.Requests), raw.TotalRequests)
+	if err != nil {
+		return nil, nil, err
+	}
+	requests := &RequestsResponse{
+		Requests:      make([]Request, 0, len(raw.Requests)),
+		TotalRequests: total,
+	}
+	var decodeErrs []RequestDecodeError
+	for _, rawRequest := range raw.Requests {
+		var request Request
+		err = json.Unmarshal(rawRequest, &request)
+		if err != nil {
+			decodeErrs = append(decodeErrs, RequestDecodeError{Raw: rawRequest, Err: err})
+			continue
+		}
+		requests.Requests = append(requests.Requests, request)
+	}
+	return requests, decodeErrs, nil
+}
+
 // GetRequestInfo gets detailed information about a particular request and its timeline
 // as seen on the CZDS dashboard page "https://czds.icann.org/zone-requests/{ID}"
 func (c *Client) GetRequestInfo(requestID string) (*RequestsInfo, error) {
+	return c.getRequestInfo(context.Background(), requestID)
+}
+
+// getRequestInfo performs the request for GetRequestInfo
+func (c *Client) getRequestInfo(ctx context.Context, requestID string) (*RequestsInfo, error) {
 	request := new(RequestsInfo)
-	err := c.jsonAPI("GET", "/czds/requests/"+requestID, nil, request)
+	err := c.jsonAPI(ctx, "GET", "/czds/requests/"+requestID, nil, request)
 	return request, err
 }
 
 // GetTLDStatus gets the current status of all TLDs and their ability to be requested
 func (c *Client) GetTLDStatus() ([]TLDStatus, error) {
+	return c.getTLDStatus(context.Background())
+}
+
+// getTLDStatus performs the request for GetTLDStatus
+func (c *Client) getTLDStatus(ctx context.Context) ([]TLDStatus, error) {
 	requests := make([]TLDStatus, 0, 20)
-	err := c.jsonAPI("GET", "/czds/tlds", nil, &requests)
+	err := c.jsonAPI(ctx, "GET", "/czds/tlds", nil, &requests)
 	return requests, err
 }
 
@@ -163,23 +375,197 @@ func (c *Client) GetTLDStatus() ([]TLDStatus, error) {
 // page "https://czds.icann.org/terms-and-conditions"
 // this is required to accept the terms and conditions when submitting a new request
 func (c *Client) GetTerms() (*Terms, error) {
+	return c.getTerms(context.Background())
+}
+
+// getTerms performs the request for GetTerms
+func (c *Client) getTerms(ctx context.Context) (*Terms, error) {
 	terms := new(Terms)
 	// this does not appear to need auth, but we auth regardless
-	err := c.jsonAPI("GET", "/czds/terms/condition", nil, terms)
+	err := c.jsonAPI(ctx, "GET", "/czds/terms/condition", nil, terms)
 	return terms, err
 }
 
+// currentTerms gets the terms and conditions to submit a request with
+// returns ErrTermsUnavailable if the server responds without a version or any content, as happens during portal issues
+func (c *Client) currentTerms(ctx context.Context) (*Terms, error) {
+	terms, err := c.getTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(terms.Version) == "" {
+		return nil, fmt.Errorf("%w: no version returned", ErrTermsUnavailable)
+	}
+	if strings.TrimSpace(terms.Content) == "" && strings.TrimSpace(terms.ContentURL) == "" {
+		return nil, fmt.Errorf("%w: no content returned for version %s", ErrTermsUnavailable, terms.Version)
+	}
+	return terms, nil
+}
+
 // SubmitRequest submits a new request for access to new zones
+// returns ErrReadOnly if the client is read only
+// returns ErrTermsChanged if request.TcVersion is not the current terms and conditions version
+// request.AdditionalFTPIps are validated and normalized with NormalizeFTPIPs() before submitting, without
+// modifying request, and a *ReasonLengthError is returned if request.Reason is shorter or longer than the client allows
 func (c *Client) SubmitRequest(request *RequestSubmission) error {
-	err := c.jsonAPI("POST", "/czds/requests/create", request, nil)
+	return c.submitRequest(context.Background(), request)
+}
+
+// submitRequest performs the request for SubmitRequest
+func (c *Client) submitRequest(ctx context.Context, request *RequestSubmission) error {
+	err := c.checkWritable()
+	if err != nil {
+		return err
+	}
+	err = c.validateReason(request.Reason)
+	if err != nil {
+		return err
+	}
+	for _, warning := range c.ReasonWarnings(request.Reason) {
+		c.logf(ctx, "warning: %s", warning)
+	}
+	// normalize into a copy so the caller's submission is left unchanged
+	submission := *request
+	if len(request.AdditionalFTPIps) > 0 {
+		submission.AdditionalFTPIps, err = NormalizeFTPIPs(request.AdditionalFTPIps)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.jsonAPI(ctx, "POST", "/czds/requests/create", &submission, nil)
+	if isTermsError(err) {
+		return fmt.Errorf("%w: %w", ErrTermsChanged, err)
+	}
 	return err
 }
 
+// submitWithCurrentTerms sets request.TcVersion to the current terms and conditions and submits it
+// with submitAcceptingTerms. Returns ErrTermsUnavailable without submitting if the terms can not be used
+func (c *Client) submitWithCurrentTerms(ctx context.Context, request *RequestSubmission) error {
+	// a read only client makes no API calls, the request itself is checked by submitRequest
+	err := c.checkWritable()
+	if err != nil {
+		return err
+	}
+	terms, err := c.currentTerms(ctx)
+	if err != nil {
+		return err
+	}
+	request.TcVersion = terms.Version
+	return c.submitAcceptingTerms(ctx, request)
+}
+
+// submitAcceptingTerms submits request, and if the terms change before the submission and AutoAcceptTerms is set,
+// the new terms are fetched and the submission is retried once
+func (c *Client) submitAcceptingTerms(ctx context.Context, request *RequestSubmission) error {
+	err := c.submitRequest(ctx, request)
+	if !errors.Is(err, ErrTermsChanged) || !c.AutoAcceptTerms {
+		return err
+	}
+
+	// terms updated mid-request, accept the new version and try again
+	terms, err := c.currentTerms(ctx)
+	if err != nil {
+		return err
+	}
+	c.logf(ctx, "terms and conditions changed from version %s to %s, resubmitting", request.TcVersion, terms.Version)
+	request.TcVersion = terms.Version
+	return c.submitRequest(ctx, request)
+}
+
 // DownloadAllRequests outputs the contents of the csv file downloaded by
 // the "Download All Requests" button on the CZDS portal to the provided output
+// transient failures are retried from the start of the report following the client's RetryPolicy.
+// A retry is only made if nothing has been written to output yet, or if output implements
+// io.Seeker and Truncate(int64) error, such as *os.File, so the partial report can be discarded first.
+// Empty reports are retried separately, see WithEmptyReportRetry()
 func (c *Client) DownloadAllRequests(output io.Writer) error {
+	return c.DownloadAllRequestsContext(context.Background(), output)
+}
+
+// DownloadAllRequestsContext is DownloadAllRequests() with a context, which can cancel the download
+// or carry a RetryOverride from WithRetryOverride()
+func (c *Client) DownloadAllRequestsContext(ctx context.Context, output io.Writer) error {
+	return c.downloadAllRequests(ctx, output)
+}
+
+// DownloadAllRequestsGzip outputs the same csv file as DownloadAllRequests() to output gzip compressed as it is downloaded
+// the gzip stream is always closed, so if the download fails output holds a valid archive of the partial report.
+// As the compressed output can not be rewound, transient failures are only retried before any of the report is received
+func (c *Client) DownloadAllRequestsGzip(output io.Writer) error {
+	return c.DownloadAllRequestsGzipContext(context.Background(), output)
+}
+
+// DownloadAllRequestsGzipContext is DownloadAllRequestsGzip() with a context like DownloadAllRequestsContext()
+func (c *Client) DownloadAllRequestsGzipContext(ctx context.Context, output io.Writer) (err error) {
+	gz := gzip.NewWriter(output)
+	defer func() {
+		closeErr := gz.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	return c.downloadAllRequests(ctx, gz)
+}
+
+// WithEmptyReportRetry retries downloading the requests report up to retries times when the server returns
+// an empty report, waiting backoff before the first retry and doubling it after each. The report is briefly empty
+// while it is being regenerated, this is separate from the client's RetryPolicy for other failures.
+// A report still empty after every retry returns ErrEmptyReport. Without this option, or with 0 retries,
+// the first empty report fails
+func WithEmptyReportRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.emptyReport = &RetryPolicy{
+			MaxRetries: retries,
+			Backoff:    backoff,
+		}
+	}
+}
+
+// downloadAllRequests downloads the requests report to output retrying transient failures and empty reports
+func (c *Client) downloadAllRequests(ctx context.Context, output io.Writer) error {
+	policy := c.retryPolicy(ctx)
+	var emptyPolicy RetryPolicy
+	if c.emptyReport != nil {
+		emptyPolicy = *c.emptyReport
+	}
+	rw := newRewindableWriter(output)
+	var empty int
+	for attempt := 0; ; {
+		err := c.downloadAllRequestsOnce(ctx, rw)
+		if errors.Is(err, ErrEmptyReport) {
+			if empty >= emptyPolicy.MaxRetries {
+				return fmt.Errorf("%w after %d attempts", err, empty+1)
+			}
+			// nothing was written so there is nothing to rewind
+			c.logf(ctx, "requests report was empty, retrying")
+			err = emptyPolicy.wait(ctx, empty)
+			if err != nil {
+				return err
+			}
+			empty++
+			continue
+		}
+		if err == nil || !policy.shouldRetry(attempt, err) {
+			return err
+		}
+		rewindErr := rw.rewind()
+		if rewindErr != nil {
+			return fmt.Errorf("%w (unable to retry: %s)", err, rewindErr)
+		}
+		c.logf(ctx, "retrying requests report after error: %s", err)
+		err = policy.wait(ctx, attempt)
+		if err != nil {
+			return err
+		}
+		attempt++
+	}
+}
+
+// downloadAllRequestsOnce makes a single attempt to download the requests report to output
+func (c *Client) downloadAllRequestsOnce(ctx context.Context, output io.Writer) error {
 	url := c.BaseURL + "/czds/requests/report"
-	resp, err := c.apiRequest(true, "GET", url, nil)
+	resp, err := c.apiRequest(ctx, true, "GET", url, nil, withoutRetry())
 	if err != nil {
 		return err
 	}
@@ -190,7 +576,7 @@ func (c *Client) DownloadAllRequests(output io.Writer) error {
 		return err
 	}
 	if n == 0 {
-		return fmt.Errorf("%s was empty", url)
+		return fmt.Errorf("%w: %s", ErrEmptyReport, url)
 	}
 
 	return nil
@@ -199,55 +585,105 @@ func (c *Client) DownloadAllRequests(output io.Writer) error {
 // RequestTLDs is a helper function that requests access to the provided tlds with the provided reason
 // TLDs provided should be marked as able to request from GetTLDStatus()
 func (c *Client) RequestTLDs(tlds []string, reason string) error {
-	// get terms
-	terms, err := c.GetTerms()
-	if err != nil {
-		return err
-	}
-
 	// submit request
 	request := &RequestSubmission{
-		TLDNames:  tlds,
-		Reason:    reason,
-		TcVersion: terms.Version,
+		TLDNames: tlds,
+		Reason:   reason,
 	}
-	err = c.SubmitRequest(request)
-	return err
+	return c.submitWithCurrentTerms(context.Background(), request)
+}
+
+// RequestAllTLDsResult describes the outcome of RequestAllTLDsDetailed()
+// all lists are de-duplicated and sorted alphabetically by A-label
+type RequestAllTLDsResult struct {
+	Requested       []string // TLDs included in the submitted request
+	SkippedApproved []string // TLDs not requested because access is already approved
+	SkippedPending  []string // TLDs not requested because a request is already submitted or pending
 }
 
 // RequestAllTLDs is a helper function to request access to all available TLDs with the provided reason
+// the returned TLDs are de-duplicated and sorted alphabetically by A-label
 func (c *Client) RequestAllTLDs(reason string) ([]string, error) {
+	result, err := c.RequestAllTLDsDetailed(reason)
+	if result == nil {
+		return nil, err
+	}
+	return result.Requested, err
+}
+
+// RequestAllTLDsDetailed requests access to all available TLDs with the provided reason like RequestAllTLDs()
+// but also reports which TLDs were skipped and why
+func (c *Client) RequestAllTLDsDetailed(reason string) (*RequestAllTLDsResult, error) {
 	// get available to request
 	status, err := c.GetTLDStatus()
 	if err != nil {
 		return nil, err
 	}
 	// check to see if any available to request
-	requestTLDs := make([]string, 0, 10)
+	result := &RequestAllTLDsResult{
+		Requested:       make([]string, 0, 10),
+		SkippedApproved: make([]string, 0),
+		SkippedPending:  make([]string, 0),
+	}
+	seen := make(map[string]bool)
 	for _, tld := range status {
+		if seen[tld.TLD] {
+			continue
+		}
+		seen[tld.TLD] = true
 		switch tld.CurrentStatus {
 		case StatusAvailable, StatusExpired, StatusDenied, StatusRevoked:
-			requestTLDs = append(requestTLDs, tld.TLD)
+			result.Requested = append(result.Requested, tld.TLD)
+		case StatusApproved:
+			result.SkippedApproved = append(result.SkippedApproved, tld.TLD)
+		case StatusSubmitted, StatusPending:
+			result.SkippedPending = append(result.SkippedPending, tld.TLD)
 		}
 	}
+	sort.Strings(result.Requested)
+	sort.Strings(result.SkippedApproved)
+	sort.Strings(result.SkippedPending)
+
 	// if none, return now
-	if len(requestTLDs) == 0 {
-		return requestTLDs, nil
+	if len(result.Requested) == 0 {
+		return result, nil
 	}
 
-	// get terms
-	terms, err := c.GetTerms()
+	// submit request
+	request := &RequestSubmission{
+		AllTLDs:  true,
+		TLDNames: result.Requested,
+		Reason:   reason,
+	}
+	err = c.submitWithCurrentTerms(context.Background(), request)
+	return result, err
+}
+
+// RenewExpiredTLDs submits a new request with the provided reason for every TLD whose access has expired
+// TLDs that expired but already have a new request submitted or pending are not expired in GetTLDStatus()
+// and are skipped, so calling this repeatedly will not submit duplicate requests.
+// Returns the renewed TLDs sorted alphabetically by A-label
+func (c *Client) RenewExpiredTLDs(reason string) ([]string, error) {
+	status, err := c.GetTLDStatus()
 	if err != nil {
 		return nil, err
 	}
+	expired := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, tld := range status {
+		if tld.CurrentStatus == StatusExpired && !seen[tld.TLD] {
+			seen[tld.TLD] = true
+			expired = append(expired, tld.TLD)
+		}
+	}
+	if len(expired) == 0 {
+		return expired, nil
+	}
+	sort.Strings(expired)
 
-	// submit request
-	request := &RequestSubmission{
-		AllTLDs:   true,
-		TLDNames:  requestTLDs,
-		Reason:    reason,
-		TcVersion: terms.Version,
+	err = c.RequestTLDs(expired, reason)
+	if err != nil {
+		return nil, err
 	}
-	err = c.SubmitRequest(request)
-	return requestTLDs, err
+	return expired, nil
 }
@@ -0,0 +1,152 @@
+package czds
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pastLastPageHandler serves 7 requests in total, but like the API reports a total of 0 for pages past the last one
+func pastLastPageHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/czds/requests/all" {
+			http.NotFound(w, r)
+			return
+		}
+		var filter RequestsFilter
+		err := json.NewDecoder(r.Body).Decode(&filter)
+		if err != nil {
+			t.Errorf("decoding filter: %s", err)
+		}
+		if filter.Pagination.Page > 0 {
+			w.Write([]byte(`{"requests":[],"totalRequests":0}`))
+			return
+		}
+		w.Write([]byte(`{"requests":[{"requestId":"1","tld":"example","status":"approved"}],"totalRequests":7}`))
+	})
+}
+
+func TestGetRequestsPastLastPage(t *testing.T) {
+	client := newTestClient(t, pastLastPageHandler(t))
+	filter := &RequestsFilter{Pagination: RequestsPagination{Size: 100, Page: 3}}
+
+	requests, err := client.GetRequests(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests.Requests) != 0 {
+		t.Errorf("got %d requests, want 0", len(requests.Requests))
+	}
+	if requests.TotalRequests != 7 {
+		t.Errorf("got TotalRequests %d, want 7", requests.TotalRequests)
+	}
+
+	tolerant, decodeErrs, err := client.GetRequestsTolerant(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decodeErrs) != 0 {
+		t.Errorf("got decode errors %v", decodeErrs)
+	}
+	if tolerant.TotalRequests != 7 {
+		t.Errorf("tolerant: got TotalRequests %d, want 7", tolerant.TotalRequests)
+	}
+	if filter.Pagination.Page != 3 {
+		t.Errorf("filter page changed to %d", filter.Pagination.Page)
+	}
+}
+
+func TestIsDownloadAllowedFrom(t *testing.T) {
+	allowlist := []string{"192.0.2.10", "2001:db8::1", "198.51.100.0/24", "2001:db8:1::/48", "not-an-ip"}
+	tests := []struct {
+		name   string
+		ftpIps []string
+		ip     string
+		want   bool
+	}{
+		{"plain v4", allowlist, "192.0.2.10", true},
+		{"other v4", allowlist, "192.0.2.11", false},
+		{"plain v6", allowlist, "2001:db8::1", true},
+		{"uncompressed v6", allowlist, "2001:0db8:0000:0000:0000:0000:0000:0001", true},
+		{"other v6", allowlist, "2001:db8::2", false},
+		{"v4 cidr", allowlist, "198.51.100.200", true},
+		{"outside v4 cidr", allowlist, "198.51.101.1", false},
+		{"v6 cidr", allowlist, "2001:db8:1:ffff::1", true},
+		{"outside v6 cidr", allowlist, "2001:db8:2::1", false},
+		{"v4-mapped v6", allowlist, "::ffff:192.0.2.10", true},
+		{"v4-mapped v6 in cidr", allowlist, "::ffff:198.51.100.1", true},
+		{"malformed entry only", []string{"not-an-ip", "10.0.0.0/33"}, "10.0.0.1", false},
+		{"empty allowlist", nil, "192.0.2.10", false},
+	}
+	for _, test := range tests {
+		info := RequestsInfo{FtpIps: test.ftpIps}
+		ip := net.ParseIP(test.ip)
+		if ip == nil {
+			t.Fatalf("%s: bad test address %q", test.name, test.ip)
+		}
+		if got := info.IsDownloadAllowedFrom(ip); got != test.want {
+			t.Errorf("%s: IsDownloadAllowedFrom(%s) = %t, want %t", test.name, test.ip, got, test.want)
+		}
+	}
+}
+
+func TestSubmitRequestWithAcceptanceCopies(t *testing.T) {
+	var submitted RequestSubmission
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/czds/requests/create" {
+			http.NotFound(w, r)
+			return
+		}
+		err := json.NewDecoder(r.Body).Decode(&submitted)
+		if err != nil {
+			t.Errorf("decoding submission: %s", err)
+		}
+	}))
+	request := &RequestSubmission{
+		TLDNames:  []string{"example"},
+		Reason:    "Researching domain abuse trends across new gTLD zones",
+		TcVersion: "1.0",
+	}
+
+	err := client.SubmitRequestWithAcceptance(request, &TermsAcceptance{Version: "2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submitted.TcVersion != "2.0" {
+		t.Errorf("submitted TcVersion %q, want 2.0", submitted.TcVersion)
+	}
+	if request.TcVersion != "1.0" {
+		t.Errorf("caller's TcVersion changed to %q", request.TcVersion)
+	}
+}
+
+func TestDownloadAllRequestsEmptyReport(t *testing.T) {
+	var attempts atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+	})
+	tests := []struct {
+		name     string
+		opts     []Option
+		attempts int32
+	}{
+		{"default", nil, 1},
+		{"retried", []Option{WithEmptyReportRetry(2, time.Millisecond)}, 3},
+	}
+	for _, test := range tests {
+		attempts.Store(0)
+		client := newTestClient(t, handler, test.opts...)
+		err := client.DownloadAllRequests(io.Discard)
+		if !errors.Is(err, ErrEmptyReport) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, ErrEmptyReport)
+		}
+		if got := attempts.Load(); got != test.attempts {
+			t.Errorf("%s: made %d attempts, want %d", test.name, got, test.attempts)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRetryPolicy is used by requests that support retries when Client.RetryPolicy is not set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	Backoff:    time.Second,
+}
+
+// RetryPolicy configures how the Client retries requests that fail with a transient error
+type RetryPolicy struct {
+	MaxRetries int           // number of retries after the first attempt, 0 disables retries
+	Backoff    time.Duration // delay before the first retry, doubled after each retry
+	// ShouldRetry reports if a failed attempt should be retried, defaults to IsTransientError
+	ShouldRetry func(error) bool
+}
+
+// RetryOverride replaces parts of the client's RetryPolicy for the operations called with a context from WithRetryOverride()
+// so each batch can retry according to the cost of repeating it, such as retrying a large mirror more than a quick refresh.
+// The client's Backoff is kept
+type RetryOverride struct {
+	MaxRetries int // number of retries after the first attempt, 0 disables retries
+	// ShouldRetry reports if a failed attempt should be retried, the client's RetryPolicy.ShouldRetry is used if nil
+	ShouldRetry func(error) bool
+}
+
+type retryOverrideKey struct{}
+
+// WithRetryOverride returns a copy of ctx carrying override, which is used instead of the client's RetryPolicy
+// by the operations called with it. This covers the zone downloads of DownloadZones(), DownloadChangedZones(),
+// DownloadZonesStream(), DownloadZonesTar() and SyncZones(), DownloadAllRequestsContext(), and the API requests of
+// every other method taking a context. Requests that change the account, such as submitting a request, are only
+// retried when called with an override as repeating them may apply them twice
+func WithRetryOverride(ctx context.Context, override RetryOverride) context.Context {
+	return context.WithValue(ctx, retryOverrideKey{}, override)
+}
+
+// retryPolicy returns the client's RetryPolicy, or DefaultRetryPolicy if not set, with any RetryOverride carried by ctx applied
+func (c *Client) retryPolicy(ctx context.Context) RetryPolicy {
+	policy := DefaultRetryPolicy
+	if c.RetryPolicy != nil {
+		policy = *c.RetryPolicy
+	}
+	if override, ok := ctx.Value(retryOverrideKey{}).(RetryOverride); ok {
+		policy.MaxRetries = override.MaxRetries
+		if override.ShouldRetry != nil {
+			policy.ShouldRetry = override.ShouldRetry
+		}
+	}
+	return policy
+}
+
+// apiRetryPolicy returns the retryPolicy() for an API request with method to url
+// requests that are not known to be read only are not retried unless ctx carries a RetryOverride
+func (c *Client) apiRetryPolicy(ctx context.Context, method, url string) RetryPolicy {
+	policy := c.retryPolicy(ctx)
+	if _, ok := ctx.Value(retryOverrideKey{}).(RetryOverride); ok {
+		return policy
+	}
+	if !readOnlyRequest(method, strings.TrimPrefix(url, c.BaseURL)) {
+		policy.MaxRetries = 0
+	}
+	return policy
+}
+
+// shouldRetry reports if the failed attempt number attempt, starting at 0, should be retried
+func (p RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if attempt >= p.MaxRetries {
+		return false
+	}
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(err)
+	}
+	return IsTransientError(err)
+}
+
+// wait sleeps before retrying the failed attempt number attempt, starting at 0
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	return sleepContext(ctx, jitterDuration(p.Backoff<<attempt, DefaultWaitJitter))
+}
+
+// IsTransientError reports if err is likely to succeed when retried
+// this is true for network errors, responses cut short, server side or rate limit HTTP statuses, and ErrTermsUnavailable
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, ErrTermsUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// truncater is implemented by writers that can be truncated, such as *os.File
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// rewindableWriter wraps a writer so a failed attempt's partial output can be discarded before retrying
+type rewindableWriter struct {
+	w       io.Writer
+	written int64
+	start   int64 // offset of w when the first attempt started, if w can be rewound
+	canSeek bool
+}
+
+// newRewindableWriter returns a rewindableWriter for w, remembering its current offset if w can seek and truncate
+func newRewindableWriter(w io.Writer) *rewindableWriter {
+	rw := &rewindableWriter{w: w}
+	seeker, ok := w.(io.Seeker)
+	if _, canTruncate := w.(truncater); ok && canTruncate {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			rw.start = start
+			rw.canSeek = true
+		}
+	}
+	return rw
+}
+
+func (rw *rewindableWriter) Write(p []byte) (int, error) {
+	n, err := rw.w.Write(p)
+	rw.written += int64(n)
+	return n, err
+}
+
+// rewind discards everything written since the first attempt started
+// returns an error if output was written and w can not be seeked and truncated
+func (rw *rewindableWriter) rewind() error {
+	if rw.written == 0 {
+		return nil
+	}
+	if !rw.canSeek {
+		return fmt.Errorf("%d bytes already written to a writer that can not be truncated", rw.written)
+	}
+	_, err := rw.w.(io.Seeker).Seek(rw.start, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = rw.w.(truncater).Truncate(rw.start)
+	if err != nil {
+		return err
+	}
+	rw.written = 0
+	return nil
+}
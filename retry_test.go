@@ -0,0 +1,119 @@
+package czds
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetryOverrideDownloadZones(t *testing.T) {
+	var attempts atomic.Int32
+	var heads atomic.Int32
+	zones := zoneHandler([]string{"example"}, &heads)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/czds/downloads/links" {
+			attempts.Add(1)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		zones.ServeHTTP(w, r)
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxRetries: 5, Backoff: time.Millisecond}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		attempts int32
+	}{
+		{"client policy", context.Background(), 6},
+		{"override", WithRetryOverride(context.Background(), RetryOverride{MaxRetries: 2}), 3},
+		{"no retries", WithRetryOverride(context.Background(), RetryOverride{}), 1},
+		{"never retry", WithRetryOverride(context.Background(), RetryOverride{
+			MaxRetries:  5,
+			ShouldRetry: func(error) bool { return false },
+		}), 1},
+	}
+	for _, test := range tests {
+		attempts.Store(0)
+		results, err := client.DownloadZones(test.ctx, t.TempDir(), nil)
+		if err == nil || len(results) != 1 || results[0].Err == nil {
+			t.Errorf("%s: got results %+v with error %v, want a failure", test.name, results, err)
+		}
+		if got := attempts.Load(); got != test.attempts {
+			t.Errorf("%s: made %d attempts, want %d", test.name, got, test.attempts)
+		}
+	}
+}
+
+func TestAPIRequestRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var failures atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"reason":"research"}` {
+				t.Errorf("attempt %d sent body %q", attempts.Load(), body)
+			}
+		}
+		w.Write([]byte(`{}`))
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}
+	submission := map[string]string{"reason": "research"}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		path     string
+		failures int32
+		attempts int32
+		ok       bool
+	}{
+		{"read retried", context.Background(), http.MethodGet, "/czds/requests/1", 2, 3, true},
+		{"read gives up", context.Background(), http.MethodGet, "/czds/requests/1", 5, 4, false},
+		{"read only POST retried", context.Background(), http.MethodPost, "/czds/requests/all", 1, 2, true},
+		{"submit not retried", context.Background(), http.MethodPost, "/czds/requests/create", 1, 1, false},
+		{"submit retried with override", WithRetryOverride(context.Background(), RetryOverride{MaxRetries: 2}), http.MethodPost, "/czds/requests/create", 2, 3, true},
+		{"read override", WithRetryOverride(context.Background(), RetryOverride{}), http.MethodGet, "/czds/requests/1", 1, 1, false},
+	}
+	for _, test := range tests {
+		attempts.Store(0)
+		failures.Store(test.failures)
+		var request interface{}
+		if test.method == http.MethodPost {
+			request = submission
+		}
+		err := client.Do(test.ctx, test.method, test.path, request, nil)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got error %v, want success %t", test.name, err, test.ok)
+		}
+		if got := attempts.Load(); got != test.attempts {
+			t.Errorf("%s: made %d attempts, want %d", test.name, got, test.attempts)
+		}
+	}
+}
+
+func TestDownloadAllRequestsContextOverride(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	client.RetryPolicy = &RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}
+
+	ctx := WithRetryOverride(context.Background(), RetryOverride{MaxRetries: 1})
+	err := client.DownloadAllRequestsContext(ctx, io.Discard)
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("made %d attempts, want 2", got)
+	}
+}
@@ -0,0 +1,67 @@
+package czds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// session is the serialized form of the client's authentication state
+type session struct {
+	AuthURL     string    `json:"authURL"`
+	AccessToken string    `json:"accessToken"`
+	Expires     time.Time `json:"expires"`
+}
+
+// ExportSession returns the client's current authentication token and its expiration so it can be cached
+// and restored with LoadSession() by a later process, avoiding authenticating on every start.
+// The client authenticates first if it does not have a valid token.
+//
+// The returned data is NOT encrypted and grants access to the account until the token expires,
+// treat it like the account's password: store it with restrictive permissions and never log it
+func (c *Client) ExportSession() ([]byte, error) {
+	err := c.checkAuth(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+	return json.Marshal(session{
+		AuthURL:     c.AuthURL,
+		AccessToken: c.auth.AccessToken,
+		Expires:     c.authExp,
+	})
+}
+
+// LoadSession restores authentication state previously returned by ExportSession()
+// if the saved token has expired or was issued by a different AuthURL it is ignored and the client
+// authenticates with its credentials as normal when next needed. Returns an error if data is malformed
+func (c *Client) LoadSession(data []byte) error {
+	var s session
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("unable to decode session: %w", err)
+	}
+	if s.AccessToken == "" {
+		return fmt.Errorf("session does not contain an access token")
+	}
+	auth := authResponse{AccessToken: s.AccessToken}
+	exp, err := auth.getExpiration()
+	if err != nil {
+		return fmt.Errorf("unable to decode session token: %w", err)
+	}
+	if s.Expires.IsZero() || exp.Before(s.Expires) {
+		s.Expires = exp
+	}
+
+	if s.AuthURL != c.AuthURL || !s.Expires.After(c.serverNow()) {
+		c.logf(context.Background(), "ignoring saved session, authenticating as needed")
+		return nil
+	}
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+	c.auth = auth
+	c.authExp = s.Expires
+	return nil
+}
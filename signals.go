@@ -0,0 +1,18 @@
+package czds
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunWithSignals calls fn with a context derived from ctx that is cancelled when the process receives SIGINT or SIGTERM
+// so an interrupted long running operation such as DownloadZones() stops cleanly: zones being written are removed
+// rather than left truncated, and the results so far are returned. Signal handling is restored once fn returns,
+// so a second signal after that terminates the process as normal. Returns the error from fn
+func RunWithSignals(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return fn(ctx)
+}
@@ -0,0 +1,181 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ZoneSyncOp is an operation planned by PlanZoneSync()
+type ZoneSyncOp string
+
+// Operations for ZoneSyncAction.Op
+const (
+	ZoneSyncDownload ZoneSyncOp = "download" // zone is missing or outdated locally
+	ZoneSyncSkip     ZoneSyncOp = "skip"     // local copy matches the server
+	ZoneSyncRemove   ZoneSyncOp = "remove"   // local zone is no longer approved for download
+)
+
+// ZoneSyncAction is a single step of the plan returned by PlanZoneSync()
+type ZoneSyncAction struct {
+	Op     ZoneSyncOp
+	TLD    string
+	URL    string // download link, empty for ZoneSyncRemove
+	Path   string // local path of the zone
+	Reason string // human readable explanation of the Op
+}
+
+// zoneFileSuffixes are the filename endings of zones saved with DefaultNameFunc
+var zoneFileSuffixes = []string{".txt.gz", ".zone"}
+
+// PlanZoneSync compares the zones available to download with the zones already saved in dir, named by DefaultNameFunc,
+// and returns the actions needed to bring dir up to date without performing them.
+// Zones are downloaded if missing locally, or if the local size differs or it is older than the server's copy.
+// No files are planned for removal, see PlanZoneSyncPrune(). Use SyncZones() to perform the plan
+func (c *Client) PlanZoneSync(dir string) ([]ZoneSyncAction, error) {
+	return c.planZoneSync(context.Background(), dir, false)
+}
+
+// PlanZoneSyncPrune plans like PlanZoneSync() and also plans removal of the zone files in dir that do not match
+// any available zone. Every file in dir ending in .zone or .txt.gz is assumed to be a zone saved by a previous sync,
+// nothing is removed until the plan is passed to SyncZones(), so only use this for a directory dedicated to the synced zones
+func (c *Client) PlanZoneSyncPrune(dir string) ([]ZoneSyncAction, error) {
+	return c.planZoneSync(context.Background(), dir, true)
+}
+
+// planZoneSync plans the sync of dir, including removals of unavailable zones if prune is set
+func (c *Client) planZoneSync(ctx context.Context, dir string, prune bool) ([]ZoneSyncAction, error) {
+	links, err := c.getLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]ZoneSyncAction, len(links))
+	errs := make([]error, len(links))
+	forEachParallel(ctx, len(links), DefaultDownloadParallel, func(i int) {
+		actions[i], errs[i] = c.planZone(ctx, dir, links[i])
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !prune {
+		return actions, nil
+	}
+
+	// remove local zones that are no longer available
+	planned := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		planned[filepath.Base(action.Path)] = true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || planned[name] {
+			continue
+		}
+		for _, suffix := range zoneFileSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				actions = append(actions, ZoneSyncAction{
+					Op:     ZoneSyncRemove,
+					TLD:    strings.TrimSuffix(name, suffix),
+					Path:   filepath.Join(dir, name),
+					Reason: "zone is no longer approved for download",
+				})
+				break
+			}
+		}
+	}
+	return actions, nil
+}
+
+// planZone decides the ZoneSyncAction for a single zone link
+func (c *Client) planZone(ctx context.Context, dir, link string) (ZoneSyncAction, error) {
+	tld := TLDFromLink(link)
+	action := ZoneSyncAction{
+		TLD: tld,
+		URL: link,
+	}
+	resp, err := c.apiRequest(ctx, true, "HEAD", link, nil)
+	if err != nil {
+		return action, err
+	}
+	resp.Body.Close()
+	info, err := downloadInfoFromResponse(link, resp)
+	if err != nil {
+		return action, err
+	}
+	action.Path = filepath.Join(dir, filepath.Base(DefaultNameFunc(tld, resp)))
+
+	local, err := os.Stat(action.Path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		action.Op = ZoneSyncDownload
+		action.Reason = "zone has not been downloaded"
+	case err != nil:
+		return action, err
+	case local.Size() != info.ContentLength:
+		action.Op = ZoneSyncDownload
+		action.Reason = fmt.Sprintf("size of local file (%d) differs from remote (%d)", local.Size(), info.ContentLength)
+	case local.ModTime().Before(info.LastModified):
+		action.Op = ZoneSyncDownload
+		action.Reason = "remote file is newer than local"
+	default:
+		action.Op = ZoneSyncSkip
+		action.Reason = "local file matches remote"
+	}
+	return action, nil
+}
+
+// SyncZones performs a plan from PlanZoneSync() or PlanZoneSyncPrune() for dir, downloading zones with the download manager
+// and removing zones that are no longer available. Zones are saved with the filenames in the plan regardless of opts.NameFunc.
+// Removals are only performed for zone files directly in dir
+func (c *Client) SyncZones(ctx context.Context, dir string, plan []ZoneSyncAction, opts *DownloadOptions) ([]ZoneResult, error) {
+	links := make([]string, 0, len(plan))
+	paths := make(map[string]string, len(plan))
+	for _, action := range plan {
+		switch action.Op {
+		case ZoneSyncDownload:
+			links = append(links, action.URL)
+			paths[action.TLD] = action.Path
+		case ZoneSyncRemove:
+			if !isSyncedZone(dir, action.Path) {
+				return nil, fmt.Errorf("refusing to remove %s, it is not a zone file in %s", action.Path, dir)
+			}
+			err := os.Remove(action.Path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	syncOpts := DownloadOptions{}
+	if opts != nil {
+		syncOpts = *opts
+	}
+	syncOpts.NameFunc = func(tld string, _ *http.Response) string {
+		return filepath.Base(paths[tld])
+	}
+	return c.downloadLinks(ctx, dir, links, &syncOpts)
+}
+
+// isSyncedZone reports if path is a file directly in dir named like a zone saved by DefaultNameFunc
+func isSyncedZone(dir, path string) bool {
+	if filepath.Clean(filepath.Dir(path)) != filepath.Clean(dir) {
+		return false
+	}
+	for _, suffix := range zoneFileSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package czds
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPlanZoneSyncRemovals(t *testing.T) {
+	var heads atomic.Int32
+	client := newTestClient(t, zoneHandler([]string{"example"}, &heads))
+	dir := t.TempDir()
+	for _, name := range []string{"stale.txt.gz", "notes.txt", "other.zone"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan, err := client.PlanZoneSync(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range plan {
+		if action.Op == ZoneSyncRemove {
+			t.Errorf("PlanZoneSync planned to remove %s", action.Path)
+		}
+	}
+
+	plan, err = client.PlanZoneSyncPrune(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := make(map[string]bool)
+	for _, action := range plan {
+		if action.Op == ZoneSyncRemove {
+			removed[filepath.Base(action.Path)] = true
+		}
+	}
+	if len(removed) != 2 || !removed["stale.txt.gz"] || !removed["other.zone"] {
+		t.Errorf("PlanZoneSyncPrune planned to remove %v, want stale.txt.gz and other.zone", removed)
+	}
+
+	_, err = client.SyncZones(context.Background(), dir, plan, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"example.txt.gz", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	for name := range removed {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed: %v", name, err)
+		}
+	}
+}
+
+func TestSyncZonesRemovesOnlyZonesInDir(t *testing.T) {
+	client := newTestClient(t, zoneHandler(nil, new(atomic.Int32)))
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "keep.zone")
+	notZone := filepath.Join(dir, "keep.txt")
+	for _, path := range []string{outside, notZone} {
+		err := os.WriteFile(path, []byte("data"), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan := []ZoneSyncAction{{Op: ZoneSyncRemove, TLD: "keep", Path: path}}
+		_, err = client.SyncZones(context.Background(), dir, plan, nil)
+		if err == nil {
+			t.Errorf("removing %s: got no error", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package czds
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestName is the name of the manifest entry written at the end of archives by DownloadZonesTar
+const ManifestName = "manifest.json"
+
+// TarOptions configures DownloadZonesTar
+// DownloadOptions.Cache is ignored as zones are never kept on disk
+type TarOptions struct {
+	DownloadOptions
+	Gzip bool // compress the archive with gzip, producing a .tar.gz
+	// TempDir is where zones are staged while downloading before being added to the archive,
+	// defaults to os.TempDir(). At most Parallel zones are staged at once
+	TempDir string
+}
+
+// DownloadZonesTar downloads all approved zones with the download manager and streams them into a tar archive on w,
+// each entry named as the zone would be saved by DownloadZones. Zones are downloaded in parallel and added to
+// the archive in order as they complete, so only the zones currently downloading or waiting to be written are
+// staged on disk. A manifest from WriteManifest() of all results is added as the last entry, ManifestName.
+// Failed zones are left out of the archive and the returned error follows the same rules as DownloadZones.
+// The Path of each result is the name of its entry in the archive
+func (c *Client) DownloadZonesTar(ctx context.Context, w io.Writer, opts *TarOptions) ([]ZoneResult, error) {
+	if opts == nil {
+		opts = &TarOptions{}
+	}
+	downloadOpts := opts.DownloadOptions
+	downloadOpts.Cache = nil
+	parallel := downloadOpts.Parallel
+	if parallel < 1 {
+		parallel = DefaultDownloadParallel
+	}
+	tempDir := opts.TempDir
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+
+	links, err := c.getLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, ErrNoApprovedTLDs
+	}
+	jobs, err := c.zoneJobs(ctx, links)
+	if err != nil {
+		return nil, err
+	}
+	less := downloadOpts.Less
+	if less == nil && downloadOpts.SortBySize {
+		less = largestFirst
+	}
+	if less != nil {
+		jobs = c.scheduleJobs(ctx, jobs, parallel, less)
+	}
+
+	var archive io.Writer = w
+	var gz *gzip.Writer
+	if opts.Gzip {
+		gz = gzip.NewWriter(w)
+		archive = gz
+	}
+	tw := tar.NewWriter(archive)
+
+	results, err := c.downloadJobsToTar(ctx, tw, tempDir, jobs, parallel, &downloadOpts)
+	if missingErr := missingRequired(links, &downloadOpts); missingErr != nil && !isArchiveError(err) {
+		err = errors.Join(missingErr, err)
+	}
+	if !isArchiveError(err) {
+		var manifest bytes.Buffer
+		writeErr := WriteManifest(&manifest, results)
+		if writeErr == nil {
+			writeErr = writeTarEntry(tw, ManifestName, int64(manifest.Len()), c.now(), &manifest)
+		}
+		if writeErr == nil {
+			writeErr = tw.Close()
+		}
+		if writeErr == nil && gz != nil {
+			writeErr = gz.Close()
+		}
+		if writeErr != nil {
+			return results, &archiveError{writeErr}
+		}
+	}
+	return results, err
+}
+
+// archiveError is a failure writing the archive itself, after which nothing more can be written
+type archiveError struct {
+	err error
+}
+
+func (e *archiveError) Error() string {
+	return fmt.Sprintf("writing zone archive: %s", e.err)
+}
+
+func (e *archiveError) Unwrap() error {
+	return e.err
+}
+
+// isArchiveError reports if err is an *archiveError
+func isArchiveError(err error) bool {
+	var archiveErr *archiveError
+	return errors.As(err, &archiveErr)
+}
+
+// downloadJobsToTar downloads jobs into tempDir with up to parallel at once and adds each to tw in order
+// a job only starts once the jobs ahead of it that are staged number fewer than parallel, so staging stays bounded
+func (c *Client) downloadJobsToTar(ctx context.Context, tw *tar.Writer, tempDir string, jobs []zoneJob, parallel int, opts *DownloadOptions) ([]ZoneResult, error) {
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	budget := newDownloadBudget(opts.Deadline, len(jobs), parallel)
+	results := make([]ZoneResult, len(jobs))
+	dirs := make([]string, len(jobs)) // staging directory of each started job, removed once it is written
+	done := make([]chan struct{}, len(jobs))
+	for n := range done {
+		done[n] = make(chan struct{})
+	}
+	// slots are taken in job order and returned once a job is written, which prevents
+	// later jobs from holding every slot while an earlier one waits to start
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	go func() {
+		for n := range jobs {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				for ; n < len(jobs); n++ {
+					results[n] = ZoneResult{TLD: TLDFromLink(jobs[n].link), URL: jobs[n].link, Err: ctx.Err()}
+					close(done[n])
+				}
+				return
+			}
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				defer close(done[n])
+				dir, err := os.MkdirTemp(tempDir, "czds-zone-")
+				if err != nil {
+					results[n] = ZoneResult{TLD: TLDFromLink(jobs[n].link), URL: jobs[n].link, Err: err}
+					return
+				}
+				dirs[n] = dir
+				results[n] = c.downloadWithBudget(ctx, budget, dir, jobs[n], opts)
+			}(n)
+		}
+	}()
+
+	var archiveErr error
+	failures := newFailureTracker(opts, cancel)
+	for n := range jobs {
+		<-done[n]
+		result := &results[n]
+		if result.Path != "" {
+			if result.Err == nil && archiveErr == nil {
+				err := addZoneToTar(tw, result)
+				if err != nil {
+					archiveErr = &archiveError{err}
+					cancel()
+				}
+			}
+			result.Path = filepath.Base(result.Path)
+		}
+		// failed jobs may have no file but still have a staging directory
+		if dirs[n] != "" {
+			os.RemoveAll(dirs[n])
+		}
+		// the slot may not have been taken if the job was skipped once ctx was done
+		select {
+		case <-slots:
+		default:
+		}
+
+		failures.record(ctx, result.Err)
+	}
+	wg.Wait()
+
+	if archiveErr != nil {
+		return results, archiveErr
+	}
+	if err := parentCtx.Err(); err != nil {
+		return results, err
+	}
+	if err := failures.err(); err != nil {
+		return results, err
+	}
+	return results, resultsError(results, opts)
+}
+
+// addZoneToTar writes the zone downloaded for result to tw named by its filename
+func addZoneToTar(tw *tar.Writer, result *ZoneResult) error {
+	file, err := os.Open(result.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	modTime := result.LastModified
+	if modTime.IsZero() {
+		modTime = result.DownloadedAt
+	}
+	return writeTarEntry(tw, filepath.Base(result.Path), stat.Size(), modTime, file)
+}
+
+// writeTarEntry writes a regular file entry named name of size bytes read from r to tw
+func writeTarEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0644,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, r, size)
+	return err
+}
@@ -0,0 +1,60 @@
+package czds
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDownloadZonesTarRemovesStagedZones(t *testing.T) {
+	var heads atomic.Int32
+	zones := zoneHandler([]string{"example", "broken", "test"}, &heads)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/broken.zone") {
+			http.Error(w, "unavailable", http.StatusForbidden)
+			return
+		}
+		zones.ServeHTTP(w, r)
+	}))
+	tempDir := t.TempDir()
+
+	var archive bytes.Buffer
+	results, err := client.DownloadZonesTar(context.Background(), &archive, &TarOptions{TempDir: tempDir})
+	if err == nil {
+		t.Error("got no error for the failed zone")
+	}
+	if len(results) != 3 || results[1].Err == nil {
+		t.Errorf("got results %+v, want broken to fail", results)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("staging left behind %s", entry.Name())
+	}
+
+	var names []string
+	tr := tar.NewReader(&archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	want := "example.txt.gz test.txt.gz " + ManifestName
+	if strings.Join(names, " ") != want {
+		t.Errorf("got archive entries %q, want %s", names, want)
+	}
+}
@@ -0,0 +1,107 @@
+package czds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// TermsAcceptance is a record of the terms and conditions accepted by AcceptTerms()
+// that can be persisted for auditing exactly what was agreed to
+type TermsAcceptance struct {
+	Version       string    `json:"version"`
+	ContentSHA256 string    `json:"contentSha256"` // hex encoded SHA-256 of Terms.Content
+	ContentURL    string    `json:"contentUrl"`
+	AcceptedAt    time.Time `json:"acceptedAt"`
+}
+
+// AcceptTerms fetches the current terms and conditions and returns a TermsAcceptance recording their
+// version and a hash of their content. Use it with SubmitRequestWithAcceptance()
+func (c *Client) AcceptTerms() (*TermsAcceptance, error) {
+	terms, err := c.currentTerms(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte(terms.Content))
+	acceptance := &TermsAcceptance{
+		Version:       terms.Version,
+		ContentSHA256: hex.EncodeToString(hash[:]),
+		ContentURL:    terms.ContentURL,
+		AcceptedAt:    c.now(),
+	}
+	return acceptance, nil
+}
+
+// SubmitRequestWithAcceptance submits request agreeing to the terms and conditions version recorded in acceptance
+// request is copied and is not modified
+func (c *Client) SubmitRequestWithAcceptance(request *RequestSubmission, acceptance *TermsAcceptance) error {
+	submission := *request
+	submission.TcVersion = acceptance.Version
+	return c.SubmitRequest(&submission)
+}
+
+// SubmitResult is the outcome of a submission made with SubmitRequestWithCorrelation() or SubmitRequestsAsync()
+type SubmitResult struct {
+	CorrelationID string
+	Request       *RequestSubmission
+	Index         int   // position of Request in the submissions passed to SubmitRequestsAsync()
+	Err           error // error submitting Request, only set by SubmitRequestsAsync()
+}
+
+// SubmitRequestWithCorrelation submits request like SubmitRequest(), tagging every log message of the submission
+// and its sub-requests with the correlation ID id. The ID is only used for client side tracing and is not sent to the API.
+// If the terms change before the submission and AutoAcceptTerms is set, the new terms are accepted and it is retried once
+func (c *Client) SubmitRequestWithCorrelation(id string, request *RequestSubmission) (SubmitResult, error) {
+	ctx := WithCorrelationID(context.Background(), id)
+	result := SubmitResult{
+		CorrelationID: id,
+		Request:       request,
+	}
+	c.logf(ctx, "submitting request for %d TLDs", len(request.TLDNames))
+	err := c.submitAcceptingTerms(ctx, request)
+	return result, err
+}
+
+// SubmitRequestsAsync submits each of submissions in order in the background and sends the result of each
+// on the returned channel, which is closed once all have been handled. Submissions are made one at a time
+// under the client's rate limit, and those without a TcVersion use the current terms and conditions.
+// Once ctx is done the remaining submissions are not made and their results have ctx.Err().
+// The channel is buffered for every result so abandoning it does not leak the background submission.
+// The submissions are copied and are not modified
+func (c *Client) SubmitRequestsAsync(ctx context.Context, submissions []RequestSubmission) <-chan SubmitResult {
+	results := make(chan SubmitResult, len(submissions))
+	go func() {
+		defer close(results)
+		for n := range submissions {
+			request := submissions[n]
+			request.TLDNames = append([]string(nil), request.TLDNames...)
+			request.AdditionalFTPIps = append([]string(nil), request.AdditionalFTPIps...)
+			result := SubmitResult{
+				CorrelationID: CorrelationID(ctx),
+				Request:       &request,
+				Index:         n,
+			}
+			if result.Err = ctx.Err(); result.Err == nil {
+				if request.TcVersion == "" {
+					result.Err = c.submitWithCurrentTerms(ctx, &request)
+				} else {
+					result.Err = c.submitAcceptingTerms(ctx, &request)
+				}
+			}
+			results <- result
+		}
+	}()
+	return results
+}
+
+// GetTermsHistory returns the versions of the terms and conditions available from the API
+// the API only provides the current version, so this always returns a single element slice.
+// It exists so audits can be written against a stable interface should historical versions become available
+func (c *Client) GetTermsHistory() ([]Terms, error) {
+	terms, err := c.GetTerms()
+	if err != nil {
+		return nil, err
+	}
+	return []Terms{*terms}, nil
+}
@@ -0,0 +1,93 @@
+package czds
+
+import (
+	"context"
+	"sync"
+)
+
+// requestThrottle limits and pauses the API requests made by a Client
+type requestThrottle struct {
+	mu      sync.Mutex
+	limiter *tokenBucket  // nil if requests are not rate limited
+	resumed chan struct{} // closed on Resume(), nil if not paused
+}
+
+// WithRateLimit limits the Client to requestsPerSec API requests per second, shared by all concurrent callers
+// the limit can be changed later with Client.SetRateLimit(). A value <= 0 disables the limit
+func WithRateLimit(requestsPerSec float64) Option {
+	return func(c *Client) {
+		c.SetRateLimit(requestsPerSec)
+	}
+}
+
+// SetRateLimit changes the Client's API request rate limit to requestsPerSec, taking effect for requests not yet started
+// this can be called while requests are running, such as to speed up a long mirror overnight. A value <= 0 disables the limit
+func (c *Client) SetRateLimit(requestsPerSec float64) {
+	c.throttle.mu.Lock()
+	defer c.throttle.mu.Unlock()
+	c.throttle.limiter = nil
+	if requestsPerSec > 0 {
+		c.throttle.limiter = newTokenBucket(requestsPerSec)
+	}
+}
+
+// Pause stops the Client from starting new API requests until Resume() is called
+// requests that are waiting block until resumed or their context is done. Responses already
+// being read, such as zone downloads in progress, are not paused
+func (c *Client) Pause() {
+	c.throttle.mu.Lock()
+	defer c.throttle.mu.Unlock()
+	if c.throttle.resumed == nil {
+		c.throttle.resumed = make(chan struct{})
+	}
+}
+
+// Resume allows API requests stopped by Pause() to continue
+func (c *Client) Resume() {
+	c.throttle.mu.Lock()
+	defer c.throttle.mu.Unlock()
+	if c.throttle.resumed != nil {
+		close(c.throttle.resumed)
+		c.throttle.resumed = nil
+	}
+}
+
+// Paused reports whether the Client is paused by Pause()
+func (c *Client) Paused() bool {
+	return c.throttle.paused()
+}
+
+// wait blocks until a request may be started under the pause state and rate limit, or ctx is done
+func (t *requestThrottle) wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		resumed, limiter := t.resumed, t.limiter
+		t.mu.Unlock()
+		if resumed == nil {
+			if limiter == nil {
+				return nil
+			}
+			err := limiter.wait(ctx, 1)
+			if err != nil {
+				return err
+			}
+			// the client may have been paused while waiting for the limit
+			if !t.paused() {
+				return nil
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumed:
+		}
+	}
+}
+
+// paused reports whether requests are currently paused
+func (t *requestThrottle) paused() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.resumed != nil
+}
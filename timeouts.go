@@ -0,0 +1,34 @@
+package czds
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithResponseHeaderTimeout sets how long to wait for the server to start responding to a request,
+// after the request is sent, before failing. This does not limit how long reading the response body takes,
+// so it can be kept short to fail fast on an unresponsive server while large zones download. The default is 10 seconds
+func WithResponseHeaderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.configureTransport(func(t *http.Transport) {
+			t.ResponseHeaderTimeout = d
+		})
+	}
+}
+
+// WithDownloadTimeout sets a deadline for each zone download as a whole, including reading the entire zone
+// use a generous value as the largest zones legitimately take many minutes. 0 disables the deadline, which is the default
+func WithDownloadTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.downloadTimeout = d
+	}
+}
+
+// downloadContext returns ctx limited by the client's download timeout, if one is set
+func (c *Client) downloadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.downloadTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.downloadTimeout)
+}
@@ -0,0 +1,111 @@
+package czds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ActiveRequest returns the request that currently represents a TLD from reqs, which should all be for the same TLD.
+// Requests that are not terminal (submitted or pending) are preferred, followed by the most recently created,
+// with ties broken by the most recently updated. The bool is false if reqs is empty
+func ActiveRequest(reqs []Request) (Request, bool) {
+	if len(reqs) == 0 {
+		return Request{}, false
+	}
+	active := reqs[0]
+	for _, request := range reqs[1:] {
+		if moreActive(&request, &active) {
+			active = request
+		}
+	}
+	return active, true
+}
+
+// moreActive reports whether a should be preferred over b by ActiveRequest()
+func moreActive(a, b *Request) bool {
+	if a.IsTerminal() != b.IsTerminal() {
+		return !a.IsTerminal()
+	}
+	if !a.Created.Equal(b.Created) {
+		return a.Created.After(b.Created)
+	}
+	return a.LastUpdated.After(b.LastUpdated)
+}
+
+// groupRequestsByTLD returns all requests grouped by their TLD
+func (c *Client) groupRequestsByTLD(ctx context.Context) (map[string][]Request, error) {
+	groups := make(map[string][]Request)
+	err := c.ForEachRequest(ctx, nil, func(request Request) error {
+		groups[request.TLD] = append(groups[request.TLD], request)
+		return nil
+	})
+	return groups, err
+}
+
+// RequestsByTLD returns the active request for every TLD the account has requested, keyed by TLD
+// when a TLD has more than one request the one returned is chosen with ActiveRequest(): a submitted or pending
+// request is preferred, then the most recently created, then the most recently updated
+func (c *Client) RequestsByTLD() (map[string]Request, error) {
+	groups, err := c.groupRequestsByTLD(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]Request, len(groups))
+	for tld, requests := range groups {
+		if request, ok := ActiveRequest(requests); ok {
+			active[tld] = request
+		}
+	}
+	return active, nil
+}
+
+// FindDuplicateRequests returns the TLDs that have more than one request along with all of their requests
+// use ActiveRequest() to find which of each TLD's requests is current when cleaning up
+func (c *Client) FindDuplicateRequests() (map[string][]Request, error) {
+	groups, err := c.groupRequestsByTLD(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for tld, requests := range groups {
+		if len(requests) < 2 {
+			delete(groups, tld)
+		}
+	}
+	return groups, nil
+}
+
+// GetRequestInfoByTLD returns the details of the active request for tld, chosen from the account's requests
+// for it with ActiveRequest(). Returns ErrNotFound if the account has no requests for tld
+func (c *Client) GetRequestInfoByTLD(tld string) (*RequestsInfo, error) {
+	return c.getRequestInfoByTLD(context.Background(), tld)
+}
+
+// getRequestInfoByTLD finds the active request for tld and fetches its details
+func (c *Client) getRequestInfoByTLD(ctx context.Context, tld string) (*RequestsInfo, error) {
+	tld = strings.ToLower(strings.TrimSuffix(tld, "."))
+	filter := &RequestsFilter{
+		Status: RequestAll,
+		Filter: tld,
+		Sort: RequestsSort{
+			Field:     SortByCreated,
+			Direction: SortDesc,
+		},
+	}
+	// the filter is a search so may also match other TLDs containing tld
+	matches := make([]Request, 0)
+	err := c.ForEachRequest(ctx, filter, func(request Request) error {
+		if strings.EqualFold(request.TLD, tld) {
+			matches = append(matches, request)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	active, ok := ActiveRequest(matches)
+	if !ok {
+		return nil, fmt.Errorf("%w: no request for %s", ErrNotFound, tld)
+	}
+	return c.getRequestInfo(ctx, active.RequestID)
+}
@@ -0,0 +1,71 @@
+package czds
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+// WithMinTLSVersion sets the minimum TLS version the Client will connect with, such as tls.VersionTLS13
+// the default is TLS 1.2
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) {
+		c.configureTransport(func(t *http.Transport) {
+			t.TLSClientConfig.MinVersion = version
+		})
+	}
+}
+
+// WithPinnedPublicKeys only allows TLS connections to servers with a verified certificate chain containing
+// one of the provided public keys. Each pin is the base64 encoded SHA-256 hash of a certificate's
+// DER encoded SubjectPublicKeyInfo, the same format as HPKP "pin-sha256" values
+func WithPinnedPublicKeys(pins ...string) Option {
+	return func(c *Client) {
+		allowed := make(map[string]bool, len(pins))
+		for _, pin := range pins {
+			allowed[pin] = true
+		}
+		c.configureTransport(func(t *http.Transport) {
+			t.TLSClientConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+				for _, chain := range cs.VerifiedChains {
+					for _, cert := range chain {
+						hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+						if allowed[base64.StdEncoding.EncodeToString(hash[:])] {
+							return nil
+						}
+					}
+				}
+				return fmt.Errorf("no pinned public key found in certificate chain for %s", cs.ServerName)
+			}
+		})
+	}
+}
+
+// configureTransport applies fn to a copy of the Client's transport so the shared default is never modified
+// if the Client's HTTPClient does not use an *http.Transport the option can not be applied
+// and all requests will fail rather than connect without the requested policy
+func (c *Client) configureTransport(fn func(*http.Transport)) {
+	base := c.httpClient()
+	var transport *http.Transport
+	switch t := base.Transport.(type) {
+	case *http.Transport:
+		transport = t.Clone()
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	default:
+		c.optionErr = fmt.Errorf("unable to configure transport of type %T", base.Transport)
+		return
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	fn(transport)
+
+	client := *base
+	client.Transport = transport
+	c.HTTPClient = &client
+}
@@ -0,0 +1,94 @@
+package czds
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// VerifyLocalZone reports if the zone file at path matches the zone for tld currently on the server
+// without downloading it. A HEAD request is made to the zone's download link and if the server provides
+// a Content-MD5 header the local file is hashed and compared to it. Otherwise the local file matches if it
+// has the same size as the server's zone and was modified no earlier than the zone's Last-Modified time,
+// which holds for zones saved by this package after the server last updated them
+func (c *Client) VerifyLocalZone(tld, path string) (bool, error) {
+	ctx := context.Background()
+	url := c.ZoneLink(tld)
+	resp, err := c.apiRequest(ctx, true, "HEAD", url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if contentMD5 := resp.Header.Get("Content-MD5"); contentMD5 != "" {
+		expected, err := decodeContentMD5(contentMD5)
+		if err != nil {
+			return false, fmt.Errorf("HEAD request to %s returned an invalid 'Content-MD5' header: %w", url, err)
+		}
+		actual, err := fileMD5(path)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(expected, actual), nil
+	}
+
+	contentLength := resp.Header.Get("Content-Length")
+	if contentLength == "" {
+		return false, fmt.Errorf("HEAD request to %s missing 'Content-Length' header", url)
+	}
+	size, err := strconv.ParseInt(contentLength, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	if size != stat.Size() {
+		return false, nil
+	}
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return false, fmt.Errorf("HEAD request to %s missing or invalid 'Last-Modified' header: %w", url, err)
+	}
+	return !stat.ModTime().Before(lastModified), nil
+}
+
+// decodeContentMD5 decodes a Content-MD5 header value, which is base64 encoded
+// but accepted hex encoded as some servers send it that way
+func decodeContentMD5(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	sum, err := base64.StdEncoding.DecodeString(value)
+	if err == nil && len(sum) == md5.Size {
+		return sum, nil
+	}
+	sum, err = hex.DecodeString(value)
+	if err == nil && len(sum) == md5.Size {
+		return sum, nil
+	}
+	return nil, fmt.Errorf("%q is not an MD5 sum", value)
+}
+
+// fileMD5 returns the MD5 sum of the file at path
+func fileMD5(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hash := md5.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
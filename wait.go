@@ -0,0 +1,235 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Defaults for WaitOptions
+const (
+	DefaultWaitInterval    = time.Minute
+	DefaultWaitMaxInterval = time.Hour
+	DefaultWaitJitter      = 0.1
+)
+
+// WaitOptions configures how WaitForApproval polls a request
+type WaitOptions struct {
+	Interval    time.Duration // time between the first polls, defaults to DefaultWaitInterval
+	MaxInterval time.Duration // largest the interval will grow to with Backoff, defaults to DefaultWaitMaxInterval
+	// Backoff multiplies the interval after every poll that finds the request still pending
+	// values <= 1 poll at a fixed Interval
+	Backoff float64
+	// Jitter randomly varies each interval by up to this fraction so concurrent waiters do not poll in lockstep
+	// 0.1 varies by +/- 10%. Defaults to DefaultWaitJitter, negative values disable jitter
+	Jitter float64
+}
+
+// WaitForApproval polls the request with requestID until it reaches a terminal status (see IsTerminalStatus())
+// or ctx is done. The returned RequestsInfo's Status should be checked as the request may have
+// reached a terminal status other than approved, such as denied.
+// Polls failing with a transient error (see IsTransientError()) are retried, doubling the wait after each consecutive
+// failure up to MaxInterval, other errors stop waiting and return the last polled RequestsInfo, if any
+func (c *Client) WaitForApproval(ctx context.Context, requestID string, opts *WaitOptions) (*RequestsInfo, error) {
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultWaitMaxInterval
+	}
+	jitter := opts.Jitter
+	if jitter == 0 {
+		jitter = DefaultWaitJitter
+	}
+
+	var last *RequestsInfo
+	errInterval := interval
+	for {
+		info, err := c.getRequestInfo(ctx, requestID)
+		if err != nil {
+			if !IsTransientError(err) {
+				return last, err
+			}
+			// keep polling through outages, backing off further after each consecutive failure
+			c.logf(ctx, "polling request %s failed, retrying in %s: %s", requestID, errInterval, err)
+			err = sleepContext(ctx, jitterDuration(errInterval, jitter))
+			if err != nil {
+				return last, err
+			}
+			errInterval *= 2
+			if errInterval > maxInterval {
+				errInterval = maxInterval
+			}
+			continue
+		}
+		last = info
+		errInterval = interval
+		if IsTerminalStatus(info.Status) {
+			return info, nil
+		}
+
+		err = sleepContext(ctx, jitterDuration(interval, jitter))
+		if err != nil {
+			return info, err
+		}
+		if opts.Backoff > 1 {
+			interval = time.Duration(float64(interval) * opts.Backoff)
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+			errInterval = interval
+		}
+	}
+}
+
+// jitterDuration returns d randomly varied by up to +/- fraction of d
+func jitterDuration(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	delta := (rand.Float64()*2 - 1) * fraction * float64(d)
+	return d + time.Duration(delta)
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx.Err() if it finished first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitForZoneChange polls the zone for tld with HEAD requests every pollInterval, varied by DefaultWaitJitter,
+// until its Last-Modified time is after knownLastModified or ctx is done.
+// Returns the new metadata of the zone so the updated copy can be downloaded
+func (c *Client) WaitForZoneChange(ctx context.Context, tld string, knownLastModified time.Time, pollInterval time.Duration) (ZoneMeta, error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultWaitInterval
+	}
+	link := c.ZoneLink(tld)
+	for {
+		resp, err := c.apiRequest(ctx, true, "HEAD", link, nil)
+		if err != nil {
+			return ZoneMeta{}, err
+		}
+		resp.Body.Close()
+		meta := zoneMetaFromResponse(link, resp)
+		if meta.LastModified.After(knownLastModified) {
+			return meta, nil
+		}
+
+		err = sleepContext(ctx, jitterDuration(pollInterval, DefaultWaitJitter))
+		if err != nil {
+			return meta, err
+		}
+	}
+}
+
+// RequestTransition is a request whose status changed between two polls of WatchRevocations()
+type RequestTransition struct {
+	Request
+	PreviousStatus string // status at the previous poll, empty if the request was not seen before
+	// Action is the most recent entry in the request's history explaining the change,
+	// nil if the request details could not be fetched
+	Action *HistoryEntry
+}
+
+// errStopRequests stops ForEachRequest early without an error
+var errStopRequests = errors.New("stop iterating requests")
+
+// WatchRevocations polls the account's requests every interval, varied by DefaultWaitJitter, and calls fn with
+// the requests that changed to revoked or denied since the previous poll, along with the history entry for the change.
+// The first poll records the current status of every request and does not call fn. Later polls only page
+// through the requests updated since the newest update already seen. Runs until ctx is done or a poll fails
+func (c *Client) WatchRevocations(ctx context.Context, interval time.Duration, fn func([]RequestTransition)) error {
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+	filter := &RequestsFilter{
+		Status: RequestAll,
+		Sort: RequestsSort{
+			Field:     SortByLastUpdated,
+			Direction: SortDesc,
+		},
+	}
+
+	statuses := make(map[string]string)
+	var watermark time.Time
+	err := c.ForEachRequest(ctx, filter, func(request Request) error {
+		statuses[request.RequestID] = request.Status
+		if request.LastUpdated.After(watermark) {
+			watermark = request.LastUpdated
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = sleepContext(ctx, jitterDuration(interval, DefaultWaitJitter))
+		if err != nil {
+			return err
+		}
+
+		transitions := make([]RequestTransition, 0)
+		newWatermark := watermark
+		err = c.ForEachRequest(ctx, filter, func(request Request) error {
+			// requests updated at the watermark are checked again in case of several updates with the same time
+			if request.LastUpdated.Before(watermark) {
+				return errStopRequests
+			}
+			if request.LastUpdated.After(newWatermark) {
+				newWatermark = request.LastUpdated
+			}
+			previous, seen := statuses[request.RequestID]
+			statuses[request.RequestID] = request.Status
+			if seen && strings.EqualFold(previous, request.Status) {
+				return nil
+			}
+			if strings.EqualFold(request.Status, RequestRevoked) || strings.EqualFold(request.Status, RequestDenied) {
+				transitions = append(transitions, RequestTransition{Request: request, PreviousStatus: previous})
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopRequests) {
+			return err
+		}
+		watermark = newWatermark
+		if len(transitions) == 0 {
+			continue
+		}
+
+		for n := range transitions {
+			info, err := c.getRequestInfo(ctx, transitions[n].RequestID)
+			if err != nil {
+				c.logf(ctx, "unable to get history of request %s: %s", transitions[n].RequestID, err)
+				continue
+			}
+			transitions[n].Action = latestHistoryEntry(info.History)
+		}
+		fn(transitions)
+	}
+}
+
+// latestHistoryEntry returns the most recent entry of history, nil if empty
+func latestHistoryEntry(history []HistoryEntry) *HistoryEntry {
+	var latest *HistoryEntry
+	for n := range history {
+		if latest == nil || history[n].Timestamp.After(latest.Timestamp) {
+			latest = &history[n]
+		}
+	}
+	return latest
+}
@@ -0,0 +1,53 @@
+package czds
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForApprovalTransientErrors(t *testing.T) {
+	var polls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch polls.Add(1) {
+		case 1, 3:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case 2:
+			w.Write([]byte(`{"requestId":"1","status":"Pending"}`))
+		default:
+			w.Write([]byte(`{"requestId":"1","status":"Approved"}`))
+		}
+	}))
+	client.RetryPolicy = &RetryPolicy{}
+
+	info, err := client.WaitForApproval(context.Background(), "1", &WaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != "Approved" {
+		t.Errorf("got status %q, want Approved", info.Status)
+	}
+	if n := polls.Load(); n != 4 {
+		t.Errorf("polled %d times, want 4", n)
+	}
+}
+
+func TestWaitForApprovalPermanentError(t *testing.T) {
+	var polls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		http.NotFound(w, r)
+	}))
+
+	_, err := client.WaitForApproval(context.Background(), "1", &WaitOptions{Interval: time.Millisecond})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("got error %v, want a 404 *StatusError", err)
+	}
+	if n := polls.Load(); n != 1 {
+		t.Errorf("polled %d times, want 1", n)
+	}
+}
@@ -0,0 +1,231 @@
+package czds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ZoneRecord is a single resource record from a zone file
+type ZoneRecord struct {
+	Name  string // owner name as written in the zone
+	TTL   uint32 // 0 if not set on the record
+	Class string // upper case, "IN" for CZDS zones
+	Type  string // upper case, ex: "NS"
+	Data  string // record data with fields separated by a single space
+	Line  int    // line number in the zone file, starting at 1
+}
+
+// ZoneParseError is returned when a zone file line can not be parsed
+type ZoneParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ZoneParseError) Error() string {
+	return fmt.Sprintf("zone line %d: %s", e.Line, e.Err)
+}
+
+func (e *ZoneParseError) Unwrap() error {
+	return e.Err
+}
+
+// maxZoneLine is the longest zone file line ParseZone accepts
+const maxZoneLine = 1024 * 1024
+
+// dnsClasses are the record classes recognized by ParseZone
+var dnsClasses = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// ParseZone streams the records of a decompressed zone file from r calling fn for each one
+// zones are expected in the format distributed by CZDS, with each record on a single line as
+// "name [ttl] [class] type data". Comments, blank lines, and $ORIGIN / $TTL directives are skipped
+// and lines starting with whitespace use the previous record's owner name. Records spanning multiple
+// lines with parentheses are not supported. Parsing stops at the first error returned by fn
+func ParseZone(r io.Reader, fn func(ZoneRecord) error) error {
+	return parseZone(r, nil, fn)
+}
+
+// StreamZoneRecords parses a decompressed zone file from r like ParseZone() but only calls fn for records
+// with one of types, such as "NS" and "DS", compared case-insensitively. The data of other records is skipped
+// without being copied, making this faster than filtering in fn. Every record is passed if types is empty
+func StreamZoneRecords(r io.Reader, types []string, fn func(ZoneRecord) error) error {
+	var filter map[string]bool
+	if len(types) > 0 {
+		filter = make(map[string]bool, len(types))
+		for _, t := range types {
+			filter[strings.ToUpper(t)] = true
+		}
+	}
+	return parseZone(r, filter, fn)
+}
+
+// parseZone parses zone records from r calling fn for records with a type in types, or all records if types is nil
+// the data of records filtered out is never copied
+func parseZone(r io.Reader, types map[string]bool, fn func(ZoneRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxZoneLine)
+	var line int
+	var lastName string
+	for scanner.Scan() {
+		line++
+		text := stripZoneComment(scanner.Text())
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "$") {
+			continue
+		}
+
+		record := ZoneRecord{Line: line}
+		if text[0] == ' ' || text[0] == '\t' {
+			if lastName == "" {
+				return &ZoneParseError{Line: line, Err: fmt.Errorf("record has no owner name")}
+			}
+			record.Name = lastName
+		} else {
+			record.Name = fields[0]
+			fields = fields[1:]
+		}
+		lastName = record.Name
+
+		// the TTL and class may be in either order and are both optional
+		for i := 0; i < 2 && len(fields) > 0; i++ {
+			if ttl, err := strconv.ParseUint(fields[0], 10, 32); err == nil {
+				record.TTL = uint32(ttl)
+				fields = fields[1:]
+			} else if class := strings.ToUpper(fields[0]); dnsClasses[class] {
+				record.Class = class
+				fields = fields[1:]
+			}
+		}
+		if len(fields) == 0 {
+			return &ZoneParseError{Line: line, Err: fmt.Errorf("record has no type")}
+		}
+		record.Type = strings.ToUpper(fields[0])
+		if types != nil && !types[record.Type] {
+			continue
+		}
+		if len(fields) < 2 {
+			return &ZoneParseError{Line: line, Err: fmt.Errorf("%s record has no data", record.Type)}
+		}
+		record.Data = strings.Join(fields[1:], " ")
+
+		err := fn(record)
+		if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &ZoneParseError{Line: line + 1, Err: err}
+	}
+	return nil
+}
+
+// stripZoneComment removes a ';' comment from a zone file line, ignoring semicolons inside quoted strings
+func stripZoneComment(line string) string {
+	var quoted, escaped bool
+	for i := 0; i < len(line); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case line[i] == '\\':
+			escaped = true
+		case line[i] == '"':
+			quoted = !quoted
+		case line[i] == ';' && !quoted:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ZoneCounts holds the statistics of a zone file computed by ZoneStats()
+type ZoneCounts struct {
+	Records int            // total number of records
+	Owners  int            // number of unique owner names
+	Types   map[string]int // number of records of each type, keyed by the upper case type such as "NS"
+}
+
+// ZoneStats streams a decompressed zone file from r and counts its records by type and its unique owner names
+// records are not retained, only the counts and the set of owner names are held in memory
+func ZoneStats(r io.Reader) (*ZoneCounts, error) {
+	counts := &ZoneCounts{
+		Types: make(map[string]int),
+	}
+	owners := make(map[string]struct{})
+	err := ParseZone(r, func(record ZoneRecord) error {
+		counts.Records++
+		counts.Types[record.Type]++
+		owners[strings.ToLower(record.Name)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts.Owners = len(owners)
+	return counts, nil
+}
+
+// lastByteReader records the last byte read from r
+type lastByteReader struct {
+	r    io.Reader
+	last byte
+	read bool
+}
+
+func (lr *lastByteReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		lr.last = p[n-1]
+		lr.read = true
+	}
+	return n, err
+}
+
+// ValidateZone streams a decompressed zone file from r and checks that it is well formed
+// every line must parse as a record with ParseZone(), record types must be valid type names,
+// the zone must contain an SOA record, and the final line must be complete.
+// Errors on a line are returned as a *ZoneParseError with the line number.
+// This catches truncated or corrupt downloads that checksums and sizes alone may not
+func ValidateZone(r io.Reader) error {
+	lr := &lastByteReader{r: r}
+	var line int
+	var hasSOA bool
+	err := ParseZone(lr, func(record ZoneRecord) error {
+		line = record.Line
+		if !validRecordType(record.Type) {
+			return &ZoneParseError{Line: record.Line, Err: fmt.Errorf("invalid record type %q", record.Type)}
+		}
+		if record.Type == "SOA" {
+			hasSOA = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !lr.read {
+		return fmt.Errorf("zone is empty")
+	}
+	if lr.last != '\n' {
+		return fmt.Errorf("zone is truncated, the last line is incomplete")
+	}
+	if !hasSOA {
+		return fmt.Errorf("zone has no SOA record after %d lines", line)
+	}
+	return nil
+}
+
+// validRecordType reports if t is a syntactically valid record type such as "NS" or "TYPE65534"
+func validRecordType(t string) bool {
+	if t == "" {
+		return false
+	}
+	for i := 0; i < len(t); i++ {
+		ch := t[i]
+		if !(ch >= 'A' && ch <= 'Z') && !(ch >= '0' && ch <= '9' && i > 0) && !(ch == '-' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
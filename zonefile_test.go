@@ -0,0 +1,128 @@
+package czds
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testZone = `$ORIGIN example.
+$TTL 86400
+; a small synthetic zone
+example.	86400	IN	SOA	a.nic.example. hostmaster.example. 1 3600 900 604800 86400
+example.	86400	IN	NS	a.nic.example.
+example.	86400	IN	NS	b.nic.example.
+foo.example.	IN	3600	NS	ns1.foo.example.
+	3600	IN	NS	ns2.foo.example.
+
+FOO.example.	3600	in	ds	12345 8 2 ABCDEF ; trailing comment
+bar.example.	NS	ns1.bar.example.
+ns1.foo.example.	3600	IN	A	192.0.2.1
+`
+
+func TestZoneStats(t *testing.T) {
+	counts, err := ZoneStats(strings.NewReader(testZone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ZoneCounts{
+		Records: 8,
+		Owners:  4,
+		Types:   map[string]int{"SOA": 1, "NS": 5, "DS": 1, "A": 1},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got %+v, want %+v", counts, want)
+	}
+}
+
+func TestParseZone(t *testing.T) {
+	zone := `example.	86400	IN	SOA	a.nic.example. hostmaster.example. 1 3600 900 604800 86400
+foo.example.	3600	IN	NS	ns1.foo.example.
+	IN	3600	NS	ns2.foo.example.
+ NS ns3.foo.example.
+bar.example.	in	DS	12345 8 2 ABCDEF ; comment
+bar.example.	3600	TXT	"v=1; not a comment" ; comment
+bar.example.	TXT	"escaped \" quote; still text"
+`
+	want := []ZoneRecord{
+		{Name: "example.", TTL: 86400, Class: "IN", Type: "SOA", Data: "a.nic.example. hostmaster.example. 1 3600 900 604800 86400", Line: 1},
+		{Name: "foo.example.", TTL: 3600, Class: "IN", Type: "NS", Data: "ns1.foo.example.", Line: 2},
+		{Name: "foo.example.", TTL: 3600, Class: "IN", Type: "NS", Data: "ns2.foo.example.", Line: 3},
+		{Name: "foo.example.", Type: "NS", Data: "ns3.foo.example.", Line: 4},
+		{Name: "bar.example.", Class: "IN", Type: "DS", Data: "12345 8 2 ABCDEF", Line: 5},
+		{Name: "bar.example.", TTL: 3600, Type: "TXT", Data: `"v=1; not a comment"`, Line: 6},
+		{Name: "bar.example.", Type: "TXT", Data: `"escaped \" quote; still text"`, Line: 7},
+	}
+	var got []ZoneRecord
+	err := ParseZone(strings.NewReader(zone), func(record ZoneRecord) error {
+		got = append(got, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got records:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestParseZoneErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		line int
+	}{
+		{"blank owner on first record", "\t3600\tIN\tNS\tns1.example.\n", 1},
+		{"no type", "example.\t3600\tIN\n", 1},
+		{"no data", "example.\tNS\tns1.example.\n\nexample.\t3600\tIN\tNS\n", 3},
+	}
+	for _, test := range tests {
+		err := ParseZone(strings.NewReader(test.zone), func(ZoneRecord) error { return nil })
+		var parseErr *ZoneParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: got error %v, want a *ZoneParseError", test.name, err)
+			continue
+		}
+		if parseErr.Line != test.line {
+			t.Errorf("%s: got error on line %d, want line %d", test.name, parseErr.Line, test.line)
+		}
+	}
+}
+
+func TestValidateZone(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		valid     bool
+		parseLine int // line of the expected *ZoneParseError, if any
+	}{
+		{name: "valid", zone: testZone, valid: true},
+		{name: "missing SOA", zone: "example.\t86400\tIN\tNS\ta.nic.example.\n"},
+		{name: "truncated last line", zone: testZone + "baz.example.\t3600\tIN\tNS\tns1.baz"},
+		{name: "truncated record", zone: testZone + "baz.example.\t3600\tIN\n", parseLine: 13},
+		{name: "invalid type", zone: testZone + "baz.example.\t3600\tIN\tN$\tns1.baz.example.\n", parseLine: 13},
+		{name: "empty"},
+	}
+	for _, test := range tests {
+		err := ValidateZone(strings.NewReader(test.zone))
+		if test.valid {
+			if err != nil {
+				t.Errorf("%s: %s", test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: got no error", test.name)
+			continue
+		}
+		var parseErr *ZoneParseError
+		isParseErr := errors.As(err, &parseErr)
+		if test.parseLine == 0 && isParseErr {
+			t.Errorf("%s: got parse error %s", test.name, err)
+		}
+		if test.parseLine > 0 && (!isParseErr || parseErr.Line != test.parseLine) {
+			t.Errorf("%s: got error %v, want a *ZoneParseError on line %d", test.name, err, test.parseLine)
+		}
+	}
+}
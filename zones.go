@@ -1,11 +1,17 @@
 package czds
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,41 +23,123 @@ type DownloadInfo struct {
 }
 
 // DownloadZone provided the zone download URL retrieved from GetLinks() downloads the zone file and
-// saves it to local disk at destinationPath
+// saves it to local disk at destinationPath. The zone is written to destinationPath+PartialSuffix
+// and renamed once complete, the partial file is removed if the download fails or is cancelled
+// If Client.CheckPrivateData is set ErrPrivateDataError is returned for zones the account lacks private data rights to
 func (c *Client) DownloadZone(url, destinationPath string) error {
-	resp, err := c.apiRequest(true, "GET", url, nil)
+	ctx := context.Background()
+	if c.CheckPrivateData {
+		err := c.privateDataError(ctx, TLDFromLink(url))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := c.downloadZone(ctx, url, destinationPath)
+	return err
+}
+
+// PartialSuffix is appended to the destination of a zone while it is being downloaded
+const PartialSuffix = ".partial"
+
+// zoneDownload holds the details of a zone saved by downloadZone
+type zoneDownload struct {
+	header      http.Header // headers of the download response
+	path        string      // where the zone was saved, empty when notModified
+	size        int64
+	sha256      string // hex encoded SHA-256 of the saved zone
+	notModified bool   // the server responded to a conditional request that the zone has not changed
+}
+
+// downloadZone downloads the zone at url to destinationPath
+// if the request is conditional and the server responds that it is not modified, destinationPath is left untouched
+func (c *Client) downloadZone(ctx context.Context, url, destinationPath string, opts ...RequestOption) (*zoneDownload, error) {
+	return c.downloadZoneTo(ctx, url, func(*http.Response) (string, error) {
+		return destinationPath, nil
+	}, opts...)
+}
+
+// downloadZoneTo downloads the zone at url to the path destination returns for the download response
+// nothing is written until the response headers arrive, and destination is not called if the zone is not modified
+func (c *Client) downloadZoneTo(ctx context.Context, url string, destination func(*http.Response) (string, error), opts ...RequestOption) (*zoneDownload, error) {
+	ctx, cancel := c.downloadContext(ctx)
+	defer cancel()
+	resp, err := c.apiRequest(ctx, true, "GET", url, nil, opts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// start the file download
-	file, err := os.Create(destinationPath)
+	download := &zoneDownload{
+		header: resp.Header,
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		download.notModified = true
+		return download, nil
+	}
+	destinationPath, err := destination(resp)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
 
-	n, err := io.Copy(file, resp.Body)
+	// start the file download
+	// the zone is written to a partial file and only moved into place once complete
+	// so an interrupted download never leaves a truncated zone at destinationPath
+	partialPath := destinationPath + PartialSuffix
+	file, err := os.Create(partialPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if n == 0 {
-		return fmt.Errorf("%s was empty", destinationPath)
+	hash := sha256.New()
+	download.size, err = io.Copy(io.MultiWriter(file, hash), c.downloadReader(ctx, resp.Body))
+	if err == nil && download.size == 0 {
+		err = fmt.Errorf("%s was empty", destinationPath)
 	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partialPath, destinationPath)
+	}
+	if err != nil {
+		os.Remove(partialPath)
+		return nil, err
+	}
+	download.path = destinationPath
+	download.sha256 = hex.EncodeToString(hash.Sum(nil))
+	c.quota.zones.Add(1)
 
-	return nil
+	return download, nil
+}
+
+// downloadReader wraps the body of a zone download in the client's bandwidth limit, if one is set,
+// and counts the bytes read towards DownloadedBytes()
+func (c *Client) downloadReader(ctx context.Context, body io.Reader) io.Reader {
+	body = &countingReader{r: body, n: &c.quota.bytes}
+	if c.downloadLimiter == nil {
+		return body
+	}
+	return c.RateLimitedReader(ctx, body)
 }
 
 // GetDownloadInfo Performs a HEAD request to the zone at url and populates a DownloadInfo struct
 // with the information returned by the headers
 func (c *Client) GetDownloadInfo(url string) (*DownloadInfo, error) {
-	resp, err := c.apiRequest(true, "HEAD", url, nil)
+	return c.getDownloadInfo(context.Background(), url)
+}
+
+// getDownloadInfo performs the HEAD request for GetDownloadInfo
+func (c *Client) getDownloadInfo(ctx context.Context, url string) (*DownloadInfo, error) {
+	resp, err := c.apiRequest(ctx, true, "HEAD", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	return downloadInfoFromResponse(url, resp)
+}
 
+// downloadInfoFromResponse populates a DownloadInfo from the headers of the HEAD request to url
+func downloadInfoFromResponse(url string, resp *http.Response) (*DownloadInfo, error) {
 	lastModifiedStr := resp.Header.Get("Last-Modified")
 	if lastModifiedStr == "" {
 		return nil, fmt.Errorf("HEAD request to %s missing 'Last-Modified' header", url)
@@ -86,8 +174,13 @@ func (c *Client) GetDownloadInfo(url string) (*DownloadInfo, error) {
 
 // GetLinks returns the DownloadLinks available to the authenticated user
 func (c *Client) GetLinks() ([]string, error) {
+	return c.getLinks(context.Background())
+}
+
+// getLinks requests the DownloadLinks for GetLinks
+func (c *Client) getLinks(ctx context.Context) ([]string, error) {
 	links := make([]string, 0, 10)
-	err := c.jsonAPI("GET", "/czds/downloads/links", nil, &links)
+	err := c.jsonAPI(ctx, "GET", "/czds/downloads/links", nil, &links)
 	if err != nil {
 		return nil, err
 	}
@@ -99,3 +192,58 @@ func (c *Client) GetLinks() ([]string, error) {
 
 	return dLinks, nil
 }
+
+// ApprovedTLDs returns the TLDs the authenticated user can download zones for, sorted alphabetically
+// returns ErrNoApprovedTLDs if there are none
+func (c *Client) ApprovedTLDs() ([]string, error) {
+	links, err := c.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, ErrNoApprovedTLDs
+	}
+	tlds := make([]string, 0, len(links))
+	for _, link := range links {
+		tlds = append(tlds, TLDFromLink(link))
+	}
+	sort.Strings(tlds)
+	return tlds, nil
+}
+
+// ZoneLink returns the download link for tld on the client's BaseURL
+func (c *Client) ZoneLink(tld string) string {
+	return c.BaseURL + "/czds/downloads/" + strings.ToLower(tld) + ".zone"
+}
+
+// precheckCatalogMaxAge is how old the catalog used by PrecheckDownload() may be
+const precheckCatalogMaxAge = 10 * time.Minute
+
+// PrecheckDownload checks that the zone for tld can be downloaded without downloading it
+// by making a HEAD request to its download link. Returns ErrSFTPOnly if the TLD is only
+// delivered over SFTP, or the typed error for the server's response such as ErrUnauthorized,
+// ErrForbidden if the account does not have access, or ErrNotFound
+func (c *Client) PrecheckDownload(tld string) error {
+	catalog, err := c.CachedTLDStatus(precheckCatalogMaxAge)
+	if err != nil {
+		return err
+	}
+	for _, status := range catalog {
+		if strings.EqualFold(status.TLD, tld) && status.SFTP {
+			return fmt.Errorf("%w: %s", ErrSFTPOnly, tld)
+		}
+	}
+	if c.CheckPrivateData {
+		err = c.privateDataError(context.Background(), tld)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.apiRequest(context.Background(), true, "HEAD", c.ZoneLink(tld), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}